@@ -0,0 +1,90 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// Aggregate summarizes a numeric attribute over the records of a query.
+// Count is the number of records holding the attribute as a Number, the
+// others are left out, Min and Max are 0 when Count is.
+type Aggregate struct {
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// Avg returns the mean of the attribute, 0 if no record held it
+func (a *Aggregate) Avg() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.Count)
+}
+
+// add accounts the attribute of one record
+func (a *Aggregate) add(v *dynamodb.AttributeValue) {
+	if v == nil || v.N == nil {
+		return
+	}
+	f, err := strconv.ParseFloat(*v.N, 64)
+	if err != nil {
+		return
+	}
+	if a.Count == 0 || f < a.Min {
+		a.Min = f
+	}
+	if a.Count == 0 || f > a.Max {
+		a.Max = f
+	}
+	a.Count++
+	a.Sum += f
+}
+
+// QueryAggregate returns the records matching keyCond and filter like Query
+// and aggregates their attr into agg page by page, so the results aren't
+// iterated twice. A nil agg only queries. agg is reset first, sums are float64
+// and lose precision past 15 significant digits.
+func QueryAggregate(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, attr string, agg *Aggregate, q QueryOptions, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryAggregate", table, opts)
+	defer op.end(&err)
+	input, err := queryInput(table, keyCond, filter, q, op.opts)
+	if err != nil {
+		return nil, err
+	}
+	if agg != nil {
+		*agg = Aggregate{}
+	}
+	var output []map[string]*dynamodb.AttributeValue
+	var scanned int64
+	for {
+		input.Limit = pageLimit(op.opts.PageSize, q.Limit, scanned)
+		if err := op.nextPage(); err != nil {
+			return nil, err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		page := keep(result.Items, op.opts.Predicate)
+		if agg != nil {
+			for _, item := range page {
+				agg.add(item[attr])
+			}
+		}
+		output = append(output, page...)
+		scanned += aws.Int64Value(result.ScannedCount)
+		if op.lastPage(result.LastEvaluatedKey) || (q.Limit > 0 && scanned >= q.Limit) {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	op.addItems(len(output))
+	return output, nil
+}
@@ -0,0 +1,560 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// MaxBatchWriteItems is the most write requests one BatchWriteItem call accepts,
+// the batch helpers never send more, retries of unprocessed items included
+const MaxBatchWriteItems = 25
+
+// batchWrite sends one BatchWriteItem chunk and retries its unprocessed items
+// with the Backoff of the call
+func batchWrite(ctx context.Context, client *dynamodb.DynamoDB, table string, requests []*dynamodb.WriteRequest, op *operation) error {
+	return batchWriteTables(ctx, client, map[string][]*dynamodb.WriteRequest{table: requests}, op)
+}
+
+// batchWriteTables sends one BatchWriteItem chunk spanning tables and retries
+// its unprocessed items with the Backoff of the call
+func batchWriteTables(ctx context.Context, client *dynamodb.DynamoDB, requests map[string][]*dynamodb.WriteRequest, op *operation) error {
+	if op.opts.Duplicates != DuplicatesUnchecked {
+		deduped := make(map[string][]*dynamodb.WriteRequest, len(requests))
+		for table, rs := range requests {
+			var err error
+			if deduped[table], err = dedupeRequests(ctx, client, table, rs, op); err != nil {
+				return err
+			}
+		}
+		requests = deduped
+	}
+	total := 0
+	for _, rs := range requests {
+		total += len(rs)
+		for _, r := range rs {
+			if r.PutRequest == nil {
+				continue
+			}
+			if err := validate(r.PutRequest.Item); err != nil {
+				return err
+			}
+		}
+	}
+	if total > MaxBatchWriteItems {
+		return fmt.Errorf("dynamodb: %d write requests exceed the batch limit of %d", total, MaxBatchWriteItems)
+	}
+	for attempt := 1; ; attempt++ {
+		input := &dynamodb.BatchWriteItemInput{
+			RequestItems:           requests,
+			ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		}
+		op.write = true
+		result, err := client.BatchWriteItemWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		op.addCapacity(result.ConsumedCapacity...)
+		left := 0
+		for _, rs := range result.UnprocessedItems {
+			left += len(rs)
+		}
+		op.addItems(total - left)
+		for _, r := range requests[op.table] {
+			if r.PutRequest != nil {
+				op.touch(r.PutRequest.Item)
+			} else if r.DeleteRequest != nil {
+				op.touch(r.DeleteRequest.Key)
+			}
+		}
+		if left == 0 {
+			return nil
+		}
+		if attempt >= op.opts.Backoff.MaxAttempts {
+			return fmt.Errorf("%w: %d left", ErrUnprocessedItems, left)
+		}
+		op.opts.logf("dynamodb: retrying %d unprocessed items on %s, attempt %d", left, strings.Join(sortedTables(result.UnprocessedItems), ", "), attempt+1)
+		op.retries++
+		if err := sleep(ctx, op.opts.Backoff.delay(attempt)); err != nil {
+			return err
+		}
+		requests, total = result.UnprocessedItems, left
+	}
+}
+
+// dedupeRequests finds the write requests of table sharing a key, it fails
+// on the first one with DuplicatesError and keeps the last one of each key
+// with DuplicatesKeepLast
+func dedupeRequests(ctx context.Context, client *dynamodb.DynamoDB, table string, requests []*dynamodb.WriteRequest, op *operation) ([]*dynamodb.WriteRequest, error) {
+	names, err := op.keyAttributes(ctx, client, table, "")
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]int, len(requests))
+	keys := make([]string, len(requests))
+	for i, r := range requests {
+		var item map[string]*dynamodb.AttributeValue
+		if r.PutRequest != nil {
+			item = r.PutRequest.Item
+		} else if r.DeleteRequest != nil {
+			item = r.DeleteRequest.Key
+		}
+		key := make(map[string]*dynamodb.AttributeValue, len(names))
+		for _, name := range names {
+			key[name] = item[name]
+		}
+		keys[i] = KeyString(key)
+		if _, dup := last[keys[i]]; dup && op.opts.Duplicates == DuplicatesError {
+			return nil, fmt.Errorf("%w: %s on %s", ErrDuplicateKey, keys[i], table)
+		}
+		last[keys[i]] = i
+	}
+	if len(last) == len(requests) {
+		return requests, nil
+	}
+	output := make([]*dynamodb.WriteRequest, 0, len(last))
+	for i, r := range requests {
+		if last[keys[i]] == i {
+			output = append(output, r)
+		}
+	}
+	return output, nil
+}
+
+// WriteRecordsTables writes the records of several tables, keyed by table
+// name, in BatchWriteItem calls shared between the tables, each holding up to
+// MaxBatchWriteItems records in all. Related records of different tables thus
+// take fewer round trips than a WriteRecords per table, but the batches are
+// not transactions.
+func WriteRecordsTables(ctx context.Context, client *dynamodb.DynamoDB, data map[string][]map[string]*dynamodb.AttributeValue, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordsTables", "", opts)
+	defer op.end(&err)
+	defer invalidateTables(op, data)
+	chunk := map[string][]*dynamodb.WriteRequest{}
+	n := 0
+	for _, table := range sortedTables(data) {
+		for _, item := range data[table] {
+			chunk[table] = append(chunk[table], &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+			if n++; n == MaxBatchWriteItems {
+				if err := batchWriteTables(ctx, client, chunk, op); err != nil {
+					return err
+				}
+				chunk, n = map[string][]*dynamodb.WriteRequest{}, 0
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return batchWriteTables(ctx, client, chunk, op)
+}
+
+// invalidateTables drops the cached queries of every table written by an
+// operation spanning tables, which end can't do without a table name
+func invalidateTables[T any](op *operation, tables map[string]T) {
+	if op.opts.Cache == nil {
+		return
+	}
+	for table := range tables {
+		op.opts.Cache.Invalidate(table)
+	}
+}
+
+// sortedTables returns the table names of m in order
+func sortedTables[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Checkpoint records the progress of ImportRecords
+// Next: index of the first record not written yet
+type Checkpoint struct {
+	Next int `json:"next"`
+}
+
+// CheckpointWriter returns a checkpoint callback appending each checkpoint
+// to w as a line of JSON
+func CheckpointWriter(w io.Writer) func(Checkpoint) error {
+	encoder := json.NewEncoder(w)
+	return func(c Checkpoint) error {
+		return encoder.Encode(c)
+	}
+}
+
+// ReadCheckpoint returns the last checkpoint written by CheckpointWriter,
+// an empty reader gives the zero Checkpoint which starts from the beginning
+func ReadCheckpoint(r io.Reader) (Checkpoint, error) {
+	var last Checkpoint
+	decoder := json.NewDecoder(r)
+	for {
+		var c Checkpoint
+		err := decoder.Decode(&c)
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return last, err
+		}
+		last = c
+	}
+}
+
+// ImportRecords writes data in batches starting at index start, after every
+// batch it calls checkpoint so a crashed import can resume from Checkpoint.Next
+// checkpoint: may be nil
+func ImportRecords(ctx context.Context, client *dynamodb.DynamoDB, table string, data []map[string]*dynamodb.AttributeValue, start int, checkpoint func(Checkpoint) error, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "ImportRecords", table, opts)
+	defer op.end(&err)
+	if start < 0 || start > len(data) {
+		return fmt.Errorf("dynamodb: start index %d out of range [0, %d]", start, len(data))
+	}
+	for i := start; i < len(data); i += MaxBatchWriteItems {
+		end := i + MaxBatchWriteItems
+		if end > len(data) {
+			end = len(data)
+		}
+		var temp []*dynamodb.WriteRequest
+		for _, v := range data[i:end] {
+			temp = append(temp, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: v}})
+		}
+		if err := batchWrite(ctx, client, table, temp, op); err != nil {
+			return err
+		}
+		if checkpoint != nil {
+			if err := checkpoint(Checkpoint{Next: end}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteRecordsWithin writes data in batches like WriteRecords until the
+// deadline, the earlier of now plus budget and the deadline of ctx, and
+// returns the records it didn't get to so the caller can enqueue them. It
+// stops before a batch that isn't expected to finish in time, judging by the
+// slowest batch so far. A batch cut off by the deadline is returned whole,
+// some of its records may be written already, which is harmless for puts.
+// budget: 0 relies on the deadline of ctx alone
+func WriteRecordsWithin(ctx context.Context, client *dynamodb.DynamoDB, table string, data []map[string]*dynamodb.AttributeValue, budget time.Duration, opts ...Option) (remaining []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "WriteRecordsWithin", table, opts)
+	defer op.end(&err)
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	deadline, bounded := ctx.Deadline()
+	var slowest time.Duration
+	for i := 0; i < len(data); i += MaxBatchWriteItems {
+		if bounded && time.Until(deadline) < slowest {
+			return data[i:], nil
+		}
+		end := i + MaxBatchWriteItems
+		if end > len(data) {
+			end = len(data)
+		}
+		var requests []*dynamodb.WriteRequest
+		for _, v := range data[i:end] {
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: v}})
+		}
+		start := time.Now()
+		if err := batchWrite(ctx, client, table, requests, op); err != nil {
+			if bounded && ctx.Err() == context.DeadlineExceeded {
+				return data[i:], nil
+			}
+			return data[i:], err
+		}
+		if d := time.Since(start); d > slowest {
+			slowest = d
+		}
+	}
+	return nil, nil
+}
+
+// ConditionalPut is a record written only when Condition holds
+// Condition: an unset expression.ConditionBuilder writes unconditionally
+type ConditionalPut struct {
+	Item      map[string]*dynamodb.AttributeValue
+	Condition expression.ConditionBuilder
+}
+
+// WriteRecordsConditional writes puts with plain batch writes when none has a
+// condition, otherwise with transactions of up to 100 records, each only
+// atomic on its own. A failed condition returns ErrConditionFailed and the
+// transactions before it stay applied.
+func WriteRecordsConditional(ctx context.Context, client *dynamodb.DynamoDB, table string, puts []ConditionalPut, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordsConditional", table, opts)
+	defer op.end(&err)
+	conditional := false
+	for _, p := range puts {
+		if isSet(p.Condition) {
+			conditional = true
+			break
+		}
+	}
+	chunk := MaxBatchWriteItems
+	if conditional {
+		chunk = MaxTransactItems
+	}
+	for i := 0; i < len(puts); i += chunk {
+		end := i + chunk
+		if end > len(puts) {
+			end = len(puts)
+		}
+		if !conditional {
+			var requests []*dynamodb.WriteRequest
+			for _, p := range puts[i:end] {
+				requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: p.Item}})
+			}
+			if err := batchWrite(ctx, client, table, requests, op); err != nil {
+				return err
+			}
+			continue
+		}
+		var items []*dynamodb.TransactWriteItem
+		for _, p := range puts[i:end] {
+			if err := validate(p.Item); err != nil {
+				return err
+			}
+			item, err := TransactPut(table, p.Item, p.Condition)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+			op.touch(p.Item)
+		}
+		if err := transactWrite(ctx, client, items, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFromChan writes the records received from ch in batches of 25 as they
+// fill up, and the last partial batch once ch is closed. It returns how many
+// records were written, stopping at the first error or when ctx is done.
+func WriteFromChan(ctx context.Context, client *dynamodb.DynamoDB, table string, ch <-chan map[string]*dynamodb.AttributeValue, opts ...Option) (written int, err error) {
+	ctx, op := startOp(ctx, "WriteFromChan", table, opts)
+	defer op.end(&err)
+	requests := make([]*dynamodb.WriteRequest, 0, MaxBatchWriteItems)
+	flush := func() error {
+		if len(requests) == 0 {
+			return nil
+		}
+		if err := batchWrite(ctx, client, table, requests, op); err != nil {
+			return err
+		}
+		written += len(requests)
+		requests = make([]*dynamodb.WriteRequest, 0, MaxBatchWriteItems)
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return written, ctx.Err()
+		case item, ok := <-ch:
+			if !ok {
+				return written, flush()
+			}
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+			if len(requests) == MaxBatchWriteItems {
+				if err := flush(); err != nil {
+					return written, err
+				}
+			}
+		}
+	}
+}
+
+// BatchGetRecords fetches the records with the given keys in chunks of 100,
+// the results follow the order of keys and missing records are left out
+func BatchGetRecords(ctx context.Context, client *dynamodb.DynamoDB, table string, keys []map[string]*dynamodb.AttributeValue, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "BatchGetRecords", table, opts)
+	defer op.end(&err)
+	return batchGet(ctx, client, table, keys, nil, op)
+}
+
+// ExistsBatch reports which of keys exist, keyed by KeyString of each key.
+// Only the key attributes are read, in chunks of 100 keys.
+func ExistsBatch(ctx context.Context, client *dynamodb.DynamoDB, table string, keys []map[string]*dynamodb.AttributeValue, opts ...Option) (_ map[string]bool, err error) {
+	ctx, op := startOp(ctx, "ExistsBatch", table, opts)
+	defer op.end(&err)
+	output := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return output, nil
+	}
+	for _, key := range keys {
+		output[KeyString(key)] = false
+	}
+	var names []string
+	for name := range keys[0] {
+		names = append(names, name)
+	}
+	items, err := batchGet(ctx, client, table, keys, names, op)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		output[KeyString(projectKey(item, keys[0]))] = true
+	}
+	return output, nil
+}
+
+// BatchGetTyped fetches the records with the given keys like BatchGetRecords
+// and unmarshals them into T
+func BatchGetTyped[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, keys []map[string]*dynamodb.AttributeValue, opts ...Option) (_ []T, err error) {
+	ctx, op := startOp(ctx, "BatchGetTyped", table, opts)
+	defer op.end(&err)
+	items, err := batchGet(ctx, client, table, keys, nil, op)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]T, 0, len(items))
+	for _, item := range items {
+		var value T
+		if err := unmarshalItem(item, &value, op.opts.Coercions); err != nil {
+			return nil, &ItemError{Key: projectKey(item, keys[0]), Err: err}
+		}
+		output = append(output, value)
+	}
+	return output, nil
+}
+
+// batchGet fetches keys in chunks of 100, projecting attrs unless empty
+func batchGet(ctx context.Context, client *dynamodb.DynamoDB, table string, keys []map[string]*dynamodb.AttributeValue, attrs []string, op *operation) ([]map[string]*dynamodb.AttributeValue, error) {
+	output, err := batchGetTables(ctx, client, map[string][]map[string]*dynamodb.AttributeValue{table: keys}, attrs, op)
+	if err != nil {
+		return nil, err
+	}
+	return output[table], nil
+}
+
+// BatchGetRecordsTables fetches the records of several tables, with their
+// keys keyed by table name, in BatchGetItem calls shared between the tables,
+// each holding up to 100 keys in all. The records of each table follow the
+// order of its keys and missing records are left out.
+func BatchGetRecordsTables(ctx context.Context, client *dynamodb.DynamoDB, keys map[string][]map[string]*dynamodb.AttributeValue, opts ...Option) (_ map[string][]map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "BatchGetRecordsTables", "", opts)
+	defer op.end(&err)
+	return batchGetTables(ctx, client, keys, nil, op)
+}
+
+// batchGetTables fetches the keys of every table in chunks of 100 keys in
+// all, projecting attrs unless empty
+func batchGetTables(ctx context.Context, client *dynamodb.DynamoDB, keys map[string][]map[string]*dynamodb.AttributeValue, attrs []string, op *operation) (map[string][]map[string]*dynamodb.AttributeValue, error) {
+	request := dynamodb.KeysAndAttributes{ConsistentRead: consistentRead(op.opts)}
+	if len(attrs) > 0 {
+		p := projection(attrs)
+		expr, err := exprParts{projection: &p}.build()
+		if err != nil {
+			return nil, err
+		}
+		request.ExpressionAttributeNames = expr.Names()
+		request.ProjectionExpression = expr.Projection()
+	}
+	type tableKey struct {
+		table string
+		key   map[string]*dynamodb.AttributeValue
+	}
+	var all []tableKey
+	for _, table := range sortedTables(keys) {
+		for _, key := range keys[table] {
+			all = append(all, tableKey{table, key})
+		}
+	}
+	// records are matched to their key by the attributes of the first key
+	found := make(map[[2]string]map[string]*dynamodb.AttributeValue, len(all))
+	for i := 0; i < len(all); i += 100 {
+		end := i + 100
+		if end > len(all) {
+			end = len(all)
+		}
+		pending := map[string]*dynamodb.KeysAndAttributes{}
+		for _, tk := range all[i:end] {
+			if pending[tk.table] == nil {
+				chunk := request
+				pending[tk.table] = &chunk
+			}
+			pending[tk.table].Keys = append(pending[tk.table].Keys, tk.key)
+		}
+		for attempt := 1; len(pending) > 0; attempt++ {
+			input := &dynamodb.BatchGetItemInput{
+				RequestItems:           pending,
+				ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+			}
+			result, err := client.BatchGetItemWithContext(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			op.addCapacity(result.ConsumedCapacity...)
+			for table, items := range result.Responses {
+				for _, item := range items {
+					if table == op.table {
+						op.touch(item)
+					}
+					found[[2]string{table, formatKey(projectKey(item, keys[table][0]))}] = item
+				}
+			}
+			pending = map[string]*dynamodb.KeysAndAttributes{}
+			left := 0
+			for table, unprocessed := range result.UnprocessedKeys {
+				if len(unprocessed.Keys) == 0 {
+					continue
+				}
+				chunk := request
+				chunk.Keys = unprocessed.Keys
+				pending[table] = &chunk
+				left += len(chunk.Keys)
+			}
+			if left == 0 {
+				break
+			}
+			if attempt >= op.opts.Backoff.MaxAttempts {
+				return nil, fmt.Errorf("%w: %d keys left", ErrUnprocessedItems, left)
+			}
+			op.opts.logf("dynamodb: retrying %d unprocessed keys on %s, attempt %d", left, strings.Join(sortedTables(pending), ", "), attempt+1)
+			op.retries++
+			if err := sleep(ctx, op.opts.Backoff.delay(attempt)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	output := make(map[string][]map[string]*dynamodb.AttributeValue, len(keys))
+	count := 0
+	for table, tableKeys := range keys {
+		items := make([]map[string]*dynamodb.AttributeValue, 0, len(tableKeys))
+		for _, key := range tableKeys {
+			if item, ok := found[[2]string{table, formatKey(key)}]; ok {
+				items = append(items, item)
+			}
+		}
+		output[table] = items
+		count += len(items)
+	}
+	op.addItems(count)
+	return output, nil
+}
+
+// projectKey returns the attributes of item named like the attributes of key
+func projectKey(item, key map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	projected := make(map[string]*dynamodb.AttributeValue, len(key))
+	for name := range key {
+		if v, ok := item[name]; ok {
+			projected[name] = v
+		}
+	}
+	return projected
+}
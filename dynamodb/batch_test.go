@@ -0,0 +1,108 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// fakeBatchWrites answers BatchWriteItem requests by processing the first
+// half of the requests of each table and leaving the rest unprocessed, it
+// counts the puts of every table and id in written
+func fakeBatchWrites(t *testing.T, written map[string]int, rounds *int) fakeHandler {
+	return func(op string, body []byte) (int, interface{}) {
+		var input dynamodb.BatchWriteItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Error(err)
+		}
+		*rounds++
+		n := 0
+		out := dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}
+		for table, rs := range input.RequestItems {
+			n += len(rs)
+			processed := (len(rs) + 1) / 2
+			for _, r := range rs[:processed] {
+				written[table+"/"+aws.StringValue(r.PutRequest.Item["id"].S)]++
+			}
+			if processed < len(rs) {
+				out.UnprocessedItems[table] = rs[processed:]
+			}
+		}
+		if n > MaxBatchWriteItems {
+			t.Errorf("got %d requests in one batch, want at most %d", n, MaxBatchWriteItems)
+		}
+		return http.StatusOK, out
+	}
+}
+
+// testItems returns n items with the ids 0 to n-1
+func testItems(n int) []map[string]*dynamodb.AttributeValue {
+	items := make([]map[string]*dynamodb.AttributeValue, n)
+	for i := range items {
+		items[i] = map[string]*dynamodb.AttributeValue{"id": {S: aws.String(fmt.Sprint(i))}}
+	}
+	return items
+}
+
+// checkWritten fails unless every item of data was written exactly once
+func checkWritten(t *testing.T, written map[string]int, data map[string][]map[string]*dynamodb.AttributeValue) {
+	t.Helper()
+	want := 0
+	for table, items := range data {
+		want += len(items)
+		for _, item := range items {
+			if n := written[table+"/"+aws.StringValue(item["id"].S)]; n != 1 {
+				t.Errorf("%s %s written %d times, want once", table, aws.StringValue(item["id"].S), n)
+			}
+		}
+	}
+	if len(written) != want {
+		t.Errorf("got %d items written, want %d", len(written), want)
+	}
+}
+
+var testBackoff = WithBackoff(BackoffConfig{Base: time.Millisecond, Max: time.Millisecond, MaxAttempts: 10})
+
+func TestWriteRecordsRetriesUnprocessedItems(t *testing.T) {
+	written, rounds := map[string]int{}, 0
+	client := newFakeClient(t, fakeBatchWrites(t, written, &rounds))
+	data := testItems(60)
+	if err := WriteRecords(client, data, "t", testBackoff); err != nil {
+		t.Fatal(err)
+	}
+	checkWritten(t, written, map[string][]map[string]*dynamodb.AttributeValue{"t": data})
+	// the chunks of 25 leave 12, 6, 3 and 1 items and take 5 rounds, the chunk
+	// of 10 leaves 5, 2 and 1 and takes 4
+	if rounds != 14 {
+		t.Errorf("got %d rounds, want 14", rounds)
+	}
+}
+
+func TestWriteRecordsTablesRetriesUnprocessedItems(t *testing.T) {
+	written, rounds := map[string]int{}, 0
+	client := newFakeClient(t, fakeBatchWrites(t, written, &rounds))
+	data := map[string][]map[string]*dynamodb.AttributeValue{"a": testItems(30), "b": testItems(17)}
+	if err := WriteRecordsTables(context.Background(), client, data, testBackoff); err != nil {
+		t.Fatal(err)
+	}
+	checkWritten(t, written, data)
+}
+
+func TestWriteRecordsUnprocessedItemsLeft(t *testing.T) {
+	written, rounds := map[string]int{}, 0
+	client := newFakeClient(t, fakeBatchWrites(t, written, &rounds))
+	err := WriteRecords(client, testItems(25), "t", WithBackoff(BackoffConfig{Base: time.Millisecond, Max: time.Millisecond, MaxAttempts: 2}))
+	if !errors.Is(err, ErrUnprocessedItems) {
+		t.Fatalf("got %v, want ErrUnprocessedItems", err)
+	}
+	if rounds != 2 || len(written) != 19 {
+		t.Errorf("got %d items written in %d rounds, want 19 in 2", len(written), rounds)
+	}
+}
@@ -0,0 +1,142 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// QueryCache keeps query results in memory for a TTL, keyed by the
+// serialized query parameters. It is opt-in per call with WithCache and safe
+// for concurrent use. Cached items are shared, callers must not modify them.
+// Expired entries are swept as new ones are stored, so distinct queries don't
+// grow the cache past the entries of one TTL.
+type QueryCache struct {
+	// MaxEntries bounds the number of entries, storing one more evicts the
+	// entry expiring first. 0 means no bound. Set it before the cache is used.
+	MaxEntries int
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	// sweepAt is the number of entries at which put sweeps expired ones
+	sweepAt int
+}
+
+type cacheEntry struct {
+	table   string
+	items   []map[string]*dynamodb.AttributeValue
+	expires time.Time
+}
+
+// NewQueryCache func returns an empty cache whose entries live for ttl
+func NewQueryCache(ttl time.Duration) *QueryCache {
+	return &QueryCache{ttl: ttl, entries: map[string]cacheEntry{}, sweepAt: minSweep}
+}
+
+// WithCache serves queries from c and stores their results in it, writes
+// given the same option invalidate the entries of their table
+func WithCache(c *QueryCache) Option {
+	return func(o *Options) {
+		o.Cache = c
+	}
+}
+
+// Invalidate drops every entry of table
+func (c *QueryCache) Invalidate(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.table == table {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Clear drops every entry
+func (c *QueryCache) Clear() {
+	c.mu.Lock()
+	c.entries = map[string]cacheEntry{}
+	c.sweepAt = minSweep
+	c.mu.Unlock()
+}
+
+func (c *QueryCache) get(table string, input interface{}) ([]map[string]*dynamodb.AttributeValue, bool) {
+	k, ok := cacheKey(table, input)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, k)
+		return nil, false
+	}
+	return append([]map[string]*dynamodb.AttributeValue(nil), e.items...), true
+}
+
+func (c *QueryCache) put(table string, input interface{}, items []map[string]*dynamodb.AttributeValue) {
+	k, ok := cacheKey(table, input)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[k]; !ok {
+		if len(c.entries) >= c.sweepAt {
+			c.sweep(now)
+		}
+		if c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+			c.evict()
+		}
+	}
+	// copy the slice, the caller gets the same one back from the query
+	items = append([]map[string]*dynamodb.AttributeValue(nil), items...)
+	c.entries[k] = cacheEntry{table: table, items: items, expires: now.Add(c.ttl)}
+}
+
+// minSweep is the fewest entries put sweeps at
+const minSweep = 64
+
+// sweep drops the expired entries, the next sweep happens once the cache
+// doubled so sweeping stays amortized constant per put
+func (c *QueryCache) sweep(now time.Time) {
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.sweepAt = 2 * len(c.entries)
+	if c.sweepAt < minSweep {
+		c.sweepAt = minSweep
+	}
+}
+
+// evict drops the entry expiring first
+func (c *QueryCache) evict() {
+	var oldest string
+	var expires time.Time
+	for k, e := range c.entries {
+		if oldest == "" || e.expires.Before(expires) {
+			oldest, expires = k, e.expires
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+// cacheKey serializes the request, encoding/json sorts map keys so equal
+// requests give equal keys
+func cacheKey(table string, input interface{}) (string, bool) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", false
+	}
+	return table + "\x00" + string(b), true
+}
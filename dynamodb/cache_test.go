@@ -0,0 +1,56 @@
+package dynamodb
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestQueryCacheSweepsExpiredEntries(t *testing.T) {
+	c := NewQueryCache(time.Hour)
+	for i := 0; i < minSweep; i++ {
+		c.put("t", i, nil)
+	}
+	for k, e := range c.entries {
+		e.expires = time.Now().Add(-time.Second)
+		c.entries[k] = e
+	}
+	for i := 0; i < 1000; i++ {
+		c.put("t", fmt.Sprint("fresh", i), nil)
+	}
+	// the first new entry swept the expired ones
+	if n := len(c.entries); n != 1000 {
+		t.Fatalf("got %d entries, want 1000", n)
+	}
+}
+
+func TestQueryCacheMaxEntries(t *testing.T) {
+	c := NewQueryCache(time.Hour)
+	c.MaxEntries = 3
+	for i := 0; i < 5; i++ {
+		c.put("t", i, nil)
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(c.entries); n != 3 {
+		t.Fatalf("got %d entries, want 3", n)
+	}
+	for i, want := range []bool{false, false, true, true, true} {
+		if _, ok := c.get("t", i); ok != want {
+			t.Errorf("entry %d cached = %v, want %v", i, ok, want)
+		}
+	}
+}
+
+func TestQueryCachePutCopies(t *testing.T) {
+	c := NewQueryCache(time.Hour)
+	items := []map[string]*dynamodb.AttributeValue{{"id": {S: aws.String("a")}}}
+	c.put("t", "q", items)
+	items[0] = nil
+	cached, ok := c.get("t", "q")
+	if !ok || cached[0] == nil {
+		t.Errorf("got %v, want the stored record", cached)
+	}
+}
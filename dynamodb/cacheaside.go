@@ -0,0 +1,55 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// CacheExpiresAttr holds the Unix time a GetOrCompute entry expires at, make
+// it the TTL attribute of the cache table so DynamoDB reaps stale entries
+const CacheExpiresAttr = "expiresAt"
+
+// GetOrCompute returns the cache entry at key if it hasn't expired, otherwise
+// it calls compute and stores the result with the key attributes for ttl.
+// The write only replaces a missing or expired entry, so when several callers
+// miss at once the first write wins and the others return its entry instead
+// of overwriting it. Every caller that missed still runs compute.
+func GetOrCompute(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, ttl time.Duration, compute func() (map[string]*dynamodb.AttributeValue, error), opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "GetOrCompute", table, opts)
+	defer op.end(&err)
+	item, err := getItem(ctx, client, table, key, op.opts.ConsistentRead, op)
+	if err != nil {
+		return nil, err
+	}
+	if _, expired, ok := ExpiresAt(item, CacheExpiresAttr); ok && !expired {
+		return item, nil
+	}
+	computed, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	entry := make(map[string]*dynamodb.AttributeValue, len(computed)+len(key)+1)
+	for k, v := range computed {
+		entry[k] = v
+	}
+	for k, v := range key {
+		entry[k] = v
+	}
+	entry[CacheExpiresAttr] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(now.Add(ttl).Unix(), 10))}
+	condition := expression.Name(CacheExpiresAttr).AttributeNotExists().
+		Or(expression.Name(CacheExpiresAttr).LessThanEqual(expression.Value(now.Unix())))
+	existing, err := putItem(ctx, client, table, entry, condition, dynamodb.ReturnValuesOnConditionCheckFailureAllOld, op)
+	if isConditionFailed(err) && existing != nil {
+		return existing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,35 @@
+package dynamodb
+
+import (
+	"math"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// readUnitSize is the number of bytes one read capacity unit covers
+const readUnitSize = 4096
+
+// EstimateQueryRCU estimates the read capacity units of a query returning
+// count items shaped like sample. A query is charged on the total size of
+// the items it evaluates rounded up to 4KB, 1 RCU per 4KB with consistent
+// reads and half as much otherwise. Filtered out items count as evaluated.
+func EstimateQueryRCU(sample map[string]*dynamodb.AttributeValue, count int, consistent bool) float64 {
+	return estimateRCU(ItemSize(sample)*count, consistent)
+}
+
+// EstimateQueryRCUBySize estimates the read capacity units of a query
+// evaluating count items of itemSize bytes, see EstimateQueryRCU
+func EstimateQueryRCUBySize(itemSize, count int, consistent bool) float64 {
+	return estimateRCU(itemSize*count, consistent)
+}
+
+func estimateRCU(total int, consistent bool) float64 {
+	units := math.Ceil(float64(total) / readUnitSize)
+	if units < 1 {
+		units = 1
+	}
+	if !consistent {
+		units /= 2
+	}
+	return units
+}
@@ -0,0 +1,114 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// chunkBudget is the list size one chunk holds, the rest of MaxItemSize is
+// left to the key and attribute names
+const chunkBudget = MaxItemSize - 8*1024
+
+// chunkSortKey returns the sort key of chunk i, padded so chunks sort in order
+func chunkSortKey(i int) string {
+	return fmt.Sprintf("%08d", i)
+}
+
+// lastChunkSortKey bounds the sort keys of the chunks, so the other items of
+// the partition, e.g. meta or v#1, are left alone
+const lastChunkSortKey = "99999999"
+
+// chunkRange is the key condition on the chunks from and after i
+func chunkRange(pk, pkVal, sk string, i int) expression.KeyConditionBuilder {
+	return expression.Key(pk).Equal(expression.Value(pkVal)).
+		And(expression.Key(sk).Between(expression.Value(chunkSortKey(i)), expression.Value(lastChunkSortKey)))
+}
+
+// WriteChunkedList stores values, a list too large for one item, in the
+// list attribute attr of as many items as needed in the partition pk = pkVal,
+// with the sequence numbers 00000000, 00000001... as sort keys, and returns
+// the number of chunks. Chunks left over from a longer list are deleted
+// afterwards, other items of the partition are kept unless their sort key
+// starts with 8 digits. The chunks are written with batches, not a transaction:
+// ReadChunkedList running at the same time, or after a failed write, can
+// return a mix of the old and new list, so give each logical list its own
+// partition and serialize its writers.
+// pk: partition key name, pkVal is sent as a string
+// sk: sort key name, a string attribute
+func WriteChunkedList(ctx context.Context, client *dynamodb.DynamoDB, table, pk, pkVal, sk, attr string, values []*dynamodb.AttributeValue, opts ...Option) (_ int, err error) {
+	ctx, op := startOp(ctx, "WriteChunkedList", table, opts)
+	defer op.end(&err)
+	var chunks [][]*dynamodb.AttributeValue
+	var chunk []*dynamodb.AttributeValue
+	size := 0
+	for i, v := range values {
+		n := 1 + valueSize(v)
+		if n > chunkBudget {
+			return 0, fmt.Errorf("%w: list element %d is %d bytes", ErrItemTooLarge, i, n)
+		}
+		if size+n > chunkBudget {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, v)
+		size += n
+	}
+	if chunk != nil || len(chunks) == 0 {
+		chunks = append(chunks, chunk)
+	}
+	var requests []*dynamodb.WriteRequest
+	for i, chunk := range chunks {
+		item := map[string]*dynamodb.AttributeValue{
+			pk:   {S: aws.String(pkVal)},
+			sk:   {S: aws.String(chunkSortKey(i))},
+			attr: {L: chunk},
+		}
+		if chunk == nil {
+			item[attr] = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}
+		}
+		requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+	}
+	stale, err := query(ctx, client, table, chunkRange(pk, pkVal, sk, len(chunks)), expression.ConditionBuilder{}, QueryOptions{ConsistentRead: true, ProjectionAttrs: []string{pk, sk}}, op)
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range stale {
+		requests = append(requests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}})
+	}
+	for i := 0; i < len(requests); i += MaxBatchWriteItems {
+		end := i + MaxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := batchWrite(ctx, client, table, requests[i:end], op); err != nil {
+			return 0, err
+		}
+	}
+	return len(chunks), nil
+}
+
+// ReadChunkedList reassembles the list written by WriteChunkedList, nil if
+// the partition holds no chunks. Items of the chunk range without attr are
+// skipped.
+func ReadChunkedList(ctx context.Context, client *dynamodb.DynamoDB, table, pk, pkVal, sk, attr string, opts ...Option) (_ []*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "ReadChunkedList", table, opts)
+	defer op.end(&err)
+	items, err := query(ctx, client, table, chunkRange(pk, pkVal, sk, 0), expression.ConditionBuilder{}, QueryOptions{ConsistentRead: op.opts.ConsistentRead, ProjectionAttrs: []string{attr}}, op)
+	if err != nil {
+		return nil, err
+	}
+	var values []*dynamodb.AttributeValue
+	for _, item := range items {
+		if v := item[attr]; v != nil && v.L != nil {
+			if values == nil {
+				values = []*dynamodb.AttributeValue{}
+			}
+			values = append(values, v.L...)
+		}
+	}
+	return values, nil
+}
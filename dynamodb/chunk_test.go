@@ -0,0 +1,103 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// testPartition is one partition keyed by sk, it answers queries with a
+// BETWEEN condition on sk and applies batch writes
+type testPartition struct {
+	t     *testing.T
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+var testBetween = regexp.MustCompile(`BETWEEN (:\w+) AND (:\w+)`)
+
+func (p *testPartition) handle(op string, body []byte) (int, interface{}) {
+	switch op {
+	case "Query":
+		var input dynamodb.QueryInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			p.t.Error(err)
+		}
+		m := testBetween.FindStringSubmatch(aws.StringValue(input.KeyConditionExpression))
+		if m == nil {
+			p.t.Errorf("unbounded key condition %q", aws.StringValue(input.KeyConditionExpression))
+			return http.StatusBadRequest, fakeError("ValidationException")
+		}
+		low, high := aws.StringValue(input.ExpressionAttributeValues[m[1]].S), aws.StringValue(input.ExpressionAttributeValues[m[2]].S)
+		var sks []string
+		for sk := range p.items {
+			if sk >= low && sk <= high {
+				sks = append(sks, sk)
+			}
+		}
+		sort.Strings(sks)
+		var out dynamodb.QueryOutput
+		for _, sk := range sks {
+			out.Items = append(out.Items, p.items[sk])
+		}
+		return http.StatusOK, out
+	case "BatchWriteItem":
+		var input dynamodb.BatchWriteItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			p.t.Error(err)
+		}
+		for _, r := range input.RequestItems["t"] {
+			if r.PutRequest != nil {
+				p.items[aws.StringValue(r.PutRequest.Item["sk"].S)] = r.PutRequest.Item
+			} else {
+				delete(p.items, aws.StringValue(r.DeleteRequest.Key["sk"].S))
+			}
+		}
+		return http.StatusOK, dynamodb.BatchWriteItemOutput{}
+	}
+	return http.StatusBadRequest, fakeError("UnknownOperationException")
+}
+
+func TestChunkedListKeepsOtherItems(t *testing.T) {
+	p := &testPartition{t: t, items: map[string]map[string]*dynamodb.AttributeValue{}}
+	for _, sk := range []string{"meta", "v#1"} {
+		p.items[sk] = map[string]*dynamodb.AttributeValue{"pk": {S: aws.String("p")}, "sk": {S: aws.String(sk)}, "data": {S: aws.String(sk)}}
+	}
+	client := newFakeClient(t, p.handle)
+	ctx := context.Background()
+	long := make([]*dynamodb.AttributeValue, 3)
+	for i := range long {
+		long[i] = &dynamodb.AttributeValue{S: aws.String(string(make([]byte, chunkBudget/2)))}
+	}
+	if n, err := WriteChunkedList(ctx, client, "t", "pk", "p", "sk", "list", long); err != nil || n != 3 {
+		t.Fatalf("got %d chunks, err %v, want 3", n, err)
+	}
+	// a stray item in the chunk range without the list attribute
+	p.items["00000007"] = map[string]*dynamodb.AttributeValue{"pk": {S: aws.String("p")}, "sk": {S: aws.String("00000007")}}
+	short := []*dynamodb.AttributeValue{{N: aws.String("1")}}
+	if n, err := WriteChunkedList(ctx, client, "t", "pk", "p", "sk", "list", short); err != nil || n != 1 {
+		t.Fatalf("got %d chunks, err %v, want 1", n, err)
+	}
+	var sks []string
+	for sk := range p.items {
+		sks = append(sks, sk)
+	}
+	sort.Strings(sks)
+	if want := []string{"00000000", "meta", "v#1"}; len(sks) != 3 || sks[0] != want[0] || sks[1] != want[1] || sks[2] != want[2] {
+		t.Errorf("got sort keys %v, want %v", sks, want)
+	}
+
+	p.items["00000004"] = map[string]*dynamodb.AttributeValue{"pk": {S: aws.String("p")}, "sk": {S: aws.String("00000004")}}
+	values, err := ReadChunkedList(ctx, client, "t", "pk", "p", "sk", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || aws.StringValue(values[0].N) != "1" {
+		t.Errorf("got %v, want [1]", values)
+	}
+}
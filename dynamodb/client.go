@@ -0,0 +1,114 @@
+package dynamodb
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ClientOptions configures the client built by NewClient
+type ClientOptions struct {
+	// Endpoint overrides the DynamoDB endpoint, e.g. DynamoDB Local
+	Endpoint string
+	// Credentials replaces the default credential chain of the SDK
+	Credentials *credentials.Credentials
+	// MaxRetries bounds the SDK retries of throttled and failed requests, 10 by default
+	MaxRetries int
+	// Timeout bounds each HTTP request, 0 means no timeout
+	Timeout time.Duration
+	// Transport sends the HTTP requests, http.DefaultTransport by default
+	Transport http.RoundTripper
+	// Record receives every request and response as a line of JSON, see
+	// NewReplayClient
+	Record io.Writer
+}
+
+// ClientOption sets a field of ClientOptions
+type ClientOption func(*ClientOptions)
+
+// WithEndpoint sends the requests to url instead of the regional endpoint
+func WithEndpoint(url string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Endpoint = url
+	}
+}
+
+// WithStaticCredentials authenticates with a fixed access key
+// token: session token of temporary credentials, may be empty
+func WithStaticCredentials(id, secret, token string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Credentials = credentials.NewStaticCredentials(id, secret, token)
+	}
+}
+
+// WithMaxRetries sets the number of SDK retries
+func WithMaxRetries(n int) ClientOption {
+	return func(o *ClientOptions) {
+		o.MaxRetries = n
+	}
+}
+
+// WithTimeout sets the HTTP request timeout
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.Timeout = d
+	}
+}
+
+// WithTransport sends the HTTP requests with t, e.g. to set proxies or
+// connection limits
+func WithTransport(t http.RoundTripper) ClientOption {
+	return func(o *ClientOptions) {
+		o.Transport = t
+	}
+}
+
+// WithRecording writes every request of the client and its response to w,
+// one Recording per line, for NewReplayClient to serve in tests
+func WithRecording(w io.Writer) ClientOption {
+	return func(o *ClientOptions) {
+		o.Record = w
+	}
+}
+
+// NewClient func builds a client for region, credentials come from the
+// default SDK chain unless set with WithStaticCredentials. Clients built
+// elsewhere work with every helper as well.
+func NewClient(region string, opts ...ClientOption) (*dynamodb.DynamoDB, error) {
+	o := ClientOptions{MaxRetries: 10}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cfg := aws.NewConfig().
+		WithRegion(region).
+		WithMaxRetries(o.MaxRetries).
+		WithHTTPClient(&http.Client{Timeout: o.Timeout})
+	if o.Endpoint != "" {
+		cfg = cfg.WithEndpoint(o.Endpoint)
+	}
+	if o.Credentials != nil {
+		cfg = cfg.WithCredentials(o.Credentials)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// set the transport after the session is built, which only loads a
+	// custom CA bundle into an *http.Transport
+	transport := sess.Config.HTTPClient.Transport
+	if o.Transport != nil {
+		transport = o.Transport
+	}
+	if o.Record != nil {
+		transport = &recorder{w: o.Record, next: transport}
+	}
+	sess.Config.HTTPClient = &http.Client{Timeout: o.Timeout, Transport: transport}
+	client := dynamodb.New(sess)
+	ObserveThrottles(client)
+	return client, nil
+}
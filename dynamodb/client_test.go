@@ -0,0 +1,46 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// fakeHandler answers one request for operation op, whose JSON input is body,
+// with a status and an output encoded as JSON
+type fakeHandler func(op string, body []byte) (int, interface{})
+
+// fakeError is the output of a request failed with code
+func fakeError(code string) interface{} {
+	return map[string]string{"__type": "com.amazonaws.dynamodb.v20120810#" + code, "message": code}
+}
+
+// newFakeClient returns a client whose requests are answered by h
+func newFakeClient(t *testing.T, h fakeHandler, opts ...ClientOption) *dynamodb.DynamoDB {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		target := r.Header.Get("X-Amz-Target")
+		status, out := h(target[strings.LastIndex(target, ".")+1:], body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	opts = append([]ClientOption{WithEndpoint(srv.URL), WithStaticCredentials("test", "test", ""), WithMaxRetries(0)}, opts...)
+	client, err := NewClient("us-east-1", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
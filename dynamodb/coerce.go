@@ -0,0 +1,81 @@
+package dynamodb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// Coercions maps top-level attribute names to the type their values are
+// converted to before unmarshaling: "S", "N" or "BOOL". Strings convert to
+// numbers and booleans, numbers and booleans convert to strings.
+//
+// It is a compatibility shim for reading historical data stored with
+// inconsistent types, not a default: values are only converted on read and
+// fixing the data with a migration remains preferable.
+type Coercions map[string]string
+
+// Apply returns a copy of item with the attributes of c converted, values
+// already of the target type, NULLs and missing attributes are left as is
+func (c Coercions) Apply(item map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	if len(c) == 0 {
+		return item, nil
+	}
+	output := make(map[string]*dynamodb.AttributeValue, len(item))
+	for name, value := range item {
+		output[name] = value
+	}
+	for name, to := range c {
+		value, ok := item[name]
+		if !ok || value == nil || aws.BoolValue(value.NULL) {
+			continue
+		}
+		coerced, err := coerce(value, to)
+		if err != nil {
+			return nil, fmt.Errorf("dynamodb: coercing %q: %w", name, err)
+		}
+		output[name] = coerced
+	}
+	return output, nil
+}
+
+func coerce(value *dynamodb.AttributeValue, to string) (*dynamodb.AttributeValue, error) {
+	switch {
+	case to == dynamodb.ScalarAttributeTypeS && value.S != nil,
+		to == dynamodb.ScalarAttributeTypeN && value.N != nil,
+		to == "BOOL" && value.BOOL != nil:
+		return value, nil
+	case to == dynamodb.ScalarAttributeTypeS && value.N != nil:
+		return &dynamodb.AttributeValue{S: value.N}, nil
+	case to == dynamodb.ScalarAttributeTypeS && value.BOOL != nil:
+		return &dynamodb.AttributeValue{S: aws.String(strconv.FormatBool(*value.BOOL))}, nil
+	case to == dynamodb.ScalarAttributeTypeN && value.S != nil:
+		s := strings.TrimSpace(*value.S)
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("%q is not a number", *value.S)
+		}
+		return &dynamodb.AttributeValue{N: aws.String(s)}, nil
+	case to == "BOOL" && value.S != nil:
+		b, err := strconv.ParseBool(strings.TrimSpace(*value.S))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", *value.S)
+		}
+		return &dynamodb.AttributeValue{BOOL: aws.Bool(b)}, nil
+	}
+	return nil, fmt.Errorf("unsupported conversion to %s", to)
+}
+
+// unmarshalItem unmarshals item into out after applying the coercions of c
+func unmarshalItem(item map[string]*dynamodb.AttributeValue, out interface{}, c Coercions) error {
+	item, err := c.Apply(item)
+	if err != nil {
+		return err
+	}
+	return dynamodbattribute.UnmarshalMap(item, out)
+}
@@ -0,0 +1,168 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// WriteRecordIfNewer writes the record only if its timestamp is newer than
+// the stored one (last writer wins). A stale write returns ErrConditionFailed,
+// which the caller can treat as a no-op.
+// attr: name of the timestamp attribute, it must be set on the record
+func WriteRecordIfNewer(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, attr string, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordIfNewer", table, opts)
+	defer op.end(&err)
+	item, err := data.Payload()
+	if err != nil {
+		return err
+	}
+	ts, ok := item[attr]
+	if !ok {
+		return fmt.Errorf("dynamodb: record has no %q attribute", attr)
+	}
+	condition := expression.Name(attr).AttributeNotExists().Or(expression.Name(attr).LessThan(expression.Value(rawValue{ts})))
+	_, err = putItem(ctx, client, table, item, condition, dynamodb.ReturnValuesOnConditionCheckFailureNone, op)
+	return err
+}
+
+// WriteRecordVersioned writes the record with optimistic locking and returns
+// its new version. An expected version of 0 creates the record with version
+// 1 if it doesn't exist yet, otherwise the stored version must equal expected
+// and is bumped. A conflict returns ErrConditionFailed.
+// attr: name of the numeric version attribute, set by the helper
+func WriteRecordVersioned(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, attr string, expected int64, opts ...Option) (_ int64, err error) {
+	ctx, op := startOp(ctx, "WriteRecordVersioned", table, opts)
+	defer op.end(&err)
+	item, err := data.Payload()
+	if err != nil {
+		return 0, err
+	}
+	condition := expression.Name(attr).AttributeNotExists()
+	if expected != 0 {
+		condition = expression.Name(attr).Equal(expression.Value(expected))
+	}
+	version := expected + 1
+	versioned := make(map[string]*dynamodb.AttributeValue, len(item)+1)
+	for name, v := range item {
+		versioned[name] = v
+	}
+	versioned[attr] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(version, 10))}
+	if _, err := putItem(ctx, client, table, versioned, condition, dynamodb.ReturnValuesOnConditionCheckFailureNone, op); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// WriteRecordIfNotExists writes the record only if no record with the same
+// key exists, otherwise it returns ErrConditionFailed
+// keyAttr: name of a key attribute of the table
+func WriteRecordIfNotExists(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, keyAttr string, opts ...Option) (err error) {
+	_, err = writeRecordIfNotExists(ctx, client, data, table, keyAttr, "WriteRecordIfNotExists", dynamodb.ReturnValuesOnConditionCheckFailureNone, opts)
+	return err
+}
+
+// WriteRecordIfNotExistsReturning writes the record like
+// WriteRecordIfNotExists and on a conflict returns the existing record
+// alongside ErrConditionFailed, in the same round trip, so the caller can
+// merge the two
+func WriteRecordIfNotExistsReturning(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, keyAttr string, opts ...Option) (existing map[string]*dynamodb.AttributeValue, err error) {
+	return writeRecordIfNotExists(ctx, client, data, table, keyAttr, "WriteRecordIfNotExistsReturning", dynamodb.ReturnValuesOnConditionCheckFailureAllOld, opts)
+}
+
+func writeRecordIfNotExists(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, keyAttr, name, onFailure string, opts []Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, name, table, opts)
+	defer op.end(&err)
+	item, err := data.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return putItem(ctx, client, table, item, expression.Name(keyAttr).AttributeNotExists(), onFailure, op)
+}
+
+// CreateWithUpdate creates the record at key with an UpdateItem instead of
+// a PutItem, so its attributes are computed by update server side, e.g. with
+// ADD counters, list_append or if_not_exists defaults shared with the later
+// updates of the record. It returns the new record, or ErrConditionFailed if
+// one already exists at key. Operands naming attributes read the record
+// before the update, which are all missing on creation, and numbers only
+// support + and -, so other computations must still happen client side.
+func CreateWithUpdate(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "CreateWithUpdate", table, opts)
+	defer op.end(&err)
+	condition := expression.Name(firstKeyName(key)).AttributeNotExists()
+	return updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueAllNew, op)
+}
+
+// WriteRecordIfUnchanged writes the record only if every attribute of
+// expected still holds its expected value, a lock over several fields. A nil
+// value expects the attribute to be absent. Any mismatch returns
+// ErrConditionFailed.
+func WriteRecordIfUnchanged(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table string, expected map[string]interface{}, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordIfUnchanged", table, opts)
+	defer op.end(&err)
+	if len(expected) == 0 {
+		return fmt.Errorf("dynamodb: no expected attributes")
+	}
+	item, err := data.Payload()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	conditions := make([]expression.ConditionBuilder, 0, len(names))
+	for _, name := range names {
+		if expected[name] == nil {
+			conditions = append(conditions, WhereNotExists(name))
+		} else {
+			conditions = append(conditions, expression.Name(name).Equal(expression.Value(expected[name])))
+		}
+	}
+	_, err = putItem(ctx, client, table, item, All(conditions...), dynamodb.ReturnValuesOnConditionCheckFailureNone, op)
+	return err
+}
+
+// putItem writes item when condition holds, on a failed condition it returns
+// the existing item if onFailure is ALL_OLD
+func putItem(ctx context.Context, client *dynamodb.DynamoDB, table string, item map[string]*dynamodb.AttributeValue, condition expression.ConditionBuilder, onFailure string, op *operation) (map[string]*dynamodb.AttributeValue, error) {
+	expr, err := exprParts{condition: &condition}.build()
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(item); err != nil {
+		return nil, err
+	}
+	input := &dynamodb.PutItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Item:                      item,
+		ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:                 aws.String(table),
+	}
+	if onFailure != dynamodb.ReturnValuesOnConditionCheckFailureNone {
+		input.ReturnValuesOnConditionCheckFailure = aws.String(onFailure)
+	}
+	op.write = true
+	result, err := client.PutItemWithContext(ctx, input)
+	if err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return failed.Item, err
+		}
+		return nil, err
+	}
+	op.addItems(1)
+	op.touch(item)
+	op.addCapacity(result.ConsumedCapacity)
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// testPayload is a record written as is
+type testPayload map[string]*dynamodb.AttributeValue
+
+func (p testPayload) Payload() (map[string]*dynamodb.AttributeValue, error) {
+	return p, nil
+}
+
+// testRecords is a table keyed by id that evaluates the conditions the
+// conditional helpers send, attribute_not_exists (#n) and #n = :v, and
+// applies updates made of SET #n = :v clauses
+type testRecords struct {
+	t       *testing.T
+	records map[string]map[string]*dynamodb.AttributeValue
+}
+
+var (
+	testEqual = regexp.MustCompile(`^(#\w+) = (:\w+)$`)
+	testSet   = regexp.MustCompile(`(#\w+) = (:\w+)`)
+)
+
+// holds evaluates condition on record, nil when it doesn't exist
+func (s *testRecords) holds(record map[string]*dynamodb.AttributeValue, condition string, names map[string]*string, values map[string]*dynamodb.AttributeValue) bool {
+	if strings.HasPrefix(condition, "attribute_not_exists (") {
+		name := aws.StringValue(names[strings.TrimSuffix(strings.TrimPrefix(condition, "attribute_not_exists ("), ")")])
+		return record[name] == nil
+	}
+	if m := testEqual.FindStringSubmatch(condition); m != nil {
+		return AttributeEqual(record[aws.StringValue(names[m[1]])], values[m[2]])
+	}
+	s.t.Errorf("unexpected condition %q", condition)
+	return false
+}
+
+func (s *testRecords) handle(op string, body []byte) (int, interface{}) {
+	switch op {
+	case "PutItem":
+		var input dynamodb.PutItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			s.t.Error(err)
+		}
+		id := aws.StringValue(input.Item["id"].S)
+		if !s.holds(s.records[id], aws.StringValue(input.ConditionExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues) {
+			return http.StatusBadRequest, fakeError(dynamodb.ErrCodeConditionalCheckFailedException)
+		}
+		s.records[id] = input.Item
+		return http.StatusOK, dynamodb.PutItemOutput{}
+	case "UpdateItem":
+		var input dynamodb.UpdateItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			s.t.Error(err)
+		}
+		id := aws.StringValue(input.Key["id"].S)
+		if input.ConditionExpression != nil && !s.holds(s.records[id], *input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues) {
+			return http.StatusBadRequest, fakeError(dynamodb.ErrCodeConditionalCheckFailedException)
+		}
+		update := aws.StringValue(input.UpdateExpression)
+		if !strings.HasPrefix(update, "SET ") {
+			s.t.Errorf("unexpected update %q", update)
+		}
+		record := s.records[id]
+		if record == nil {
+			record = map[string]*dynamodb.AttributeValue{"id": input.Key["id"]}
+		}
+		for _, m := range testSet.FindAllStringSubmatch(update, -1) {
+			record[aws.StringValue(input.ExpressionAttributeNames[m[1]])] = input.ExpressionAttributeValues[m[2]]
+		}
+		s.records[id] = record
+		if aws.StringValue(input.ReturnValues) != dynamodb.ReturnValueAllNew {
+			return http.StatusOK, dynamodb.UpdateItemOutput{}
+		}
+		return http.StatusOK, dynamodb.UpdateItemOutput{Attributes: record}
+	}
+	return http.StatusBadRequest, fakeError("UnknownOperationException")
+}
+
+func TestWriteRecordVersioned(t *testing.T) {
+	store := &testRecords{t: t, records: map[string]map[string]*dynamodb.AttributeValue{}}
+	client := newFakeClient(t, store.handle)
+	ctx := context.Background()
+	record := testPayload{"id": {S: aws.String("a")}}
+	steps := []struct {
+		expected int64
+		want     int64
+		err      error
+	}{
+		{0, 1, nil},                // new record
+		{0, 0, ErrConditionFailed}, // created concurrently
+		{1, 2, nil},
+		{1, 0, ErrConditionFailed}, // stale version
+		{2, 3, nil},
+	}
+	for i, step := range steps {
+		version, err := WriteRecordVersioned(ctx, client, record, "t", "version", step.expected)
+		if !errors.Is(err, step.err) || version != step.want {
+			t.Errorf("step %d: got version %d, err %v, want %d, %v", i, version, err, step.want, step.err)
+		}
+	}
+	if got := aws.StringValue(store.records["a"]["version"].N); got != "3" {
+		t.Errorf("got stored version %s, want 3", got)
+	}
+}
+
+func TestCreateWithUpdate(t *testing.T) {
+	store := &testRecords{t: t, records: map[string]map[string]*dynamodb.AttributeValue{}}
+	client := newFakeClient(t, store.handle)
+	ctx := context.Background()
+	key := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}}
+	update := expression.Set(expression.Name("status"), expression.Value("new")).Set(expression.Name("total"), expression.Value(3))
+	record, err := CreateWithUpdate(ctx, client, "t", key, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(record["status"].S) != "new" || aws.StringValue(record["total"].N) != "3" || aws.StringValue(record["id"].S) != "a" {
+		t.Errorf("got %v, want the new record", record)
+	}
+	update = expression.Set(expression.Name("status"), expression.Value("again"))
+	if _, err := CreateWithUpdate(ctx, client, "t", key, update); !errors.Is(err, ErrConditionFailed) {
+		t.Fatalf("got %v, want ErrConditionFailed", err)
+	}
+	if got := aws.StringValue(store.records["a"]["status"].S); got != "new" {
+		t.Errorf("got status %q after the conflict, want new", got)
+	}
+}
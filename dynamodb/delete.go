@@ -0,0 +1,120 @@
+package dynamodb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// DeleteRecord deletes the record with the given key and reports whether it
+// existed, a missing record is not an error
+// key: DynamoDB key of the record
+func DeleteRecord(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, opts ...Option) (_ bool, err error) {
+	ctx, op := startOp(ctx, "DeleteRecord", table, opts)
+	defer op.end(&err)
+	condition := expression.Name(firstKeyName(key)).AttributeExists()
+	return deleteIf(ctx, client, table, key, condition, op)
+}
+
+// DeleteIf deletes the record only when attr equals expected and reports
+// whether it did, e.g. to release a lock only if you own it.
+// WithConditionError returns ErrConditionFailed instead of false.
+func DeleteIf(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, attr string, expected interface{}, opts ...Option) (_ bool, err error) {
+	ctx, op := startOp(ctx, "DeleteIf", table, opts)
+	defer op.end(&err)
+	condition := expression.Name(attr).Equal(expression.Value(expected))
+	return deleteIf(ctx, client, table, key, condition, op)
+}
+
+// deleteIf deletes the record when condition holds and reports whether it did
+func deleteIf(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, condition expression.ConditionBuilder, op *operation) (bool, error) {
+	expr, err := exprParts{condition: &condition}.build()
+	if err != nil {
+		return false, err
+	}
+	input := &dynamodb.DeleteItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       key,
+		ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:                 aws.String(table),
+	}
+	op.write = true
+	result, err := client.DeleteItemWithContext(ctx, input)
+	if isConditionFailed(err) && !op.opts.ConditionError {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	op.addItems(1)
+	op.touch(key)
+	op.addCapacity(result.ConsumedCapacity)
+	return true, nil
+}
+
+// firstKeyName returns the first key attribute name in sorted order, any key
+// attribute works for an existence check
+func firstKeyName(key map[string]*dynamodb.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// DeleteByQuery deletes every record matching keyCond and filter in batches
+// of 25 and returns how many it deleted. Only the key attributes are read.
+// index: DynamoDB index name, empty queries the base table
+func DeleteByQuery(ctx context.Context, client *dynamodb.DynamoDB, table, index string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, opts ...Option) (deleted int, err error) {
+	ctx, op := startOp(ctx, "DeleteByQuery", table, opts)
+	defer op.end(&err)
+	names, err := op.keyAttributes(ctx, client, table, "")
+	if err != nil {
+		return 0, err
+	}
+	input, err := queryInput(table, keyCond, filter, QueryOptions{IndexName: index, ProjectionAttrs: names}, op.opts)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if err := op.nextPage(); err != nil {
+			return deleted, err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			return deleted, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		for i := 0; i < len(result.Items); i += MaxBatchWriteItems {
+			end := i + MaxBatchWriteItems
+			if end > len(result.Items) {
+				end = len(result.Items)
+			}
+			requests := make([]*dynamodb.WriteRequest, 0, end-i)
+			for _, item := range result.Items[i:end] {
+				key := make(map[string]*dynamodb.AttributeValue, len(names))
+				for _, name := range names {
+					key[name] = item[name]
+				}
+				requests = append(requests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}})
+			}
+			if err := batchWrite(ctx, client, table, requests, op); err != nil {
+				return deleted, err
+			}
+			deleted += len(requests)
+		}
+		if op.lastPage(result.LastEvaluatedKey) {
+			return deleted, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,39 @@
+package dynamodb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestDeleteByQuerySinglePage(t *testing.T) {
+	queries, writes := 0, 0
+	pages := fakePages(t, 3, &queries)
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		switch op {
+		case "DescribeTable":
+			return http.StatusOK, dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+				KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+			}}
+		case "BatchWriteItem":
+			writes++
+			return http.StatusOK, dynamodb.BatchWriteItemOutput{}
+		}
+		return pages(op, body)
+	})
+	var next map[string]*dynamodb.AttributeValue
+	deleted, err := DeleteByQuery(context.Background(), client, "t", "", expression.Key("id").Equal(expression.Value("a")), expression.ConditionBuilder{}, WithSinglePage(&next))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 || queries != 1 || writes != 1 {
+		t.Errorf("got %d deleted in %d queries and %d writes, want 1, 1 and 1", deleted, queries, writes)
+	}
+	if next == nil {
+		t.Error("got no next key after the first page")
+	}
+}
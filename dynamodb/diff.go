@@ -0,0 +1,48 @@
+package dynamodb
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// Diff returns the SET and REMOVE actions turning old into new, so a sync
+// only writes the attributes that changed. The key attributes of both items
+// must be equal. Identical items give an empty builder, which fails to build,
+// check with Changed first.
+func Diff(old, new map[string]*dynamodb.AttributeValue) expression.UpdateBuilder {
+	var update expression.UpdateBuilder
+	for _, name := range changedNames(old, new) {
+		if v, ok := new[name]; ok {
+			update = update.Set(expression.Name(name), expression.Value(rawValue{v}))
+		} else {
+			update = update.Remove(expression.Name(name))
+		}
+	}
+	return update
+}
+
+// Changed reports whether old and new differ in any attribute, comparing
+// values with AttributeEqual
+func Changed(old, new map[string]*dynamodb.AttributeValue) bool {
+	return len(changedNames(old, new)) > 0
+}
+
+// changedNames returns the sorted names of the attributes set, changed or
+// removed in new
+func changedNames(old, new map[string]*dynamodb.AttributeValue) []string {
+	var names []string
+	for name, v := range new {
+		if !AttributeEqual(old[name], v) {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,14 @@
+// Package dynamodb wraps the AWS SDK DynamoDB client with helpers for writing,
+// querying, scanning and updating records.
+//
+// Attribute names passed to the helpers, in keys, conditions, filters,
+// projections and updates, are always sent as #placeholders, mostly through
+// expression.Name or expression.Key, so reserved words such as name, status,
+// type or timestamp can be used everywhere without escaping.
+//
+// Records are marshaled and unmarshaled with dynamodbattribute, so custom
+// types implementing dynamodbattribute.Marshaler and Unmarshaler, e.g. an
+// enum stored as a string, round-trip through Put, MarshalItem and the typed
+// helpers, also when nested in structs. Coercions given with WithCoercions
+// are applied before UnmarshalDynamoDBAttributeValue is called.
+package dynamodb
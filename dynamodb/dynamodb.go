@@ -1,6 +1,7 @@
 package dynamodb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 )
 
@@ -24,40 +26,83 @@ type Payloads interface {
 // WriteRecord func writes only one record at a time
 // data: Payload interface
 // table: DynamoDB table name
-func WriteRecord(client *dynamodb.DynamoDB, data Payload, table string) error {
+func WriteRecord(client *dynamodb.DynamoDB, data Payload, table string, opts ...Option) (err error) {
+	ctx, op := startOp(context.Background(), "WriteRecord", table, opts)
+	defer op.end(&err)
 	item, err := data.Payload()
 	if err != nil {
 		return err
 	}
-	input := &dynamodb.PutItemInput{Item: item, TableName: aws.String(table)}
-	_, err = client.PutItem(input)
+	if err := validate(item); err != nil {
+		return err
+	}
+	input := &dynamodb.PutItemInput{
+		Item:                   item,
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	op.write = true
+	result, err := client.PutItemWithContext(ctx, input)
 	if err != nil {
 		return err
 	}
+	op.addItems(1)
+	op.touch(item)
+	op.addCapacity(result.ConsumedCapacity)
+	return nil
+}
+
+// Put marshals any struct with dynamodbattribute.MarshalMap and writes it,
+// use WriteRecord for records with custom marshaling
+// table: DynamoDB table name
+// v: struct or map to write
+func Put(ctx context.Context, client *dynamodb.DynamoDB, table string, v interface{}, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "Put", table, opts)
+	defer op.end(&err)
+	item, err := dynamodbattribute.MarshalMap(v)
+	if err != nil {
+		return err
+	}
+	if err := validate(item); err != nil {
+		return err
+	}
+	input := &dynamodb.PutItemInput{
+		Item:                   item,
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	op.write = true
+	result, err := client.PutItemWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	op.addItems(1)
+	op.touch(item)
+	op.addCapacity(result.ConsumedCapacity)
 	return nil
 }
 
 // WriteRecords func writes a bunch of record into DynamoDB
-func WriteRecords(client *dynamodb.DynamoDB, data []map[string]*dynamodb.AttributeValue, table string) error {
-	length := int(math.Ceil(float64(len(data)) / float64(25)))
+func WriteRecords(client *dynamodb.DynamoDB, data []map[string]*dynamodb.AttributeValue, table string, opts ...Option) (err error) {
+	ctx, op := startOp(context.Background(), "WriteRecords", table, opts)
+	defer op.end(&err)
+	length := int(math.Ceil(float64(len(data)) / float64(MaxBatchWriteItems)))
 	for i := 0; i < length; i++ {
 		if i < length-1 {
 			var temp []*dynamodb.WriteRequest
-			for _, v := range data[i*25 : (i+1)*25] {
+			for _, v := range data[i*MaxBatchWriteItems : (i+1)*MaxBatchWriteItems] {
 				temp = append(temp, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: v}})
 			}
-			input := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]*dynamodb.WriteRequest{table: temp}}
-			_, err := client.BatchWriteItem(input)
+			err := batchWrite(ctx, client, table, temp, op)
 			if err != nil {
 				return err
 			}
 		} else {
 			var temp []*dynamodb.WriteRequest
-			for _, v := range data[i*25:] {
+			for _, v := range data[i*MaxBatchWriteItems:] {
 				temp = append(temp, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: v}})
 			}
-			input := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]*dynamodb.WriteRequest{table: temp}}
-			_, err := client.BatchWriteItem(input)
+			err := batchWrite(ctx, client, table, temp, op)
 			if err != nil {
 				return err
 			}
@@ -69,69 +114,28 @@ func WriteRecords(client *dynamodb.DynamoDB, data []map[string]*dynamodb.Attribu
 // QueryRecords will return a list of records according to a specific condition
 // table: DynamoDB table name
 // index: DynamoDB index name
-// key: DynamoDB key name
-// value: DynamoDB value of key
-func QueryRecords(client *dynamodb.DynamoDB, table, index, key, value string, condition expression.ConditionBuilder) ([]map[string]*dynamodb.AttributeValue, error) {
+// key: DynamoDB key name, reserved words are fine
+// value: DynamoDB value of key, always sent as a string, use Query for number keys
+func QueryRecords(client *dynamodb.DynamoDB, table, index, key, value string, condition expression.ConditionBuilder, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(context.Background(), "QueryRecords", table, opts)
+	defer op.end(&err)
 	keyCondition := expression.Key(key).Equal(expression.Value(value))
-	expr, err := expression.NewBuilder().WithFilter(condition).WithKeyCondition(keyCondition).Build()
-	if err != nil {
-		return nil, err
-	}
-	input := &dynamodb.QueryInput{
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		KeyConditionExpression:    expr.KeyCondition(),
-		FilterExpression:          expr.Filter(),
-		IndexName:                 aws.String(index),
-		TableName:                 aws.String(table),
-	}
-	var output []map[string]*dynamodb.AttributeValue
-	for {
-		result, err := client.Query(input)
-		if err != nil {
-			return nil, err
-		}
-		output = append(output, result.Items...)
-		if result.LastEvaluatedKey == nil {
-			break
-		}
-		input.ExclusiveStartKey = result.LastEvaluatedKey
-	}
-	return output, nil
+	return query(ctx, client, table, keyCondition, condition, QueryOptions{IndexName: index}, op)
 }
 
 // QueryRecordsWithFilter func
-func QueryRecordWithFilter(client *dynamodb.DynamoDB, table string, condition expression.KeyConditionBuilder, filter expression.ConditionBuilder) ([]map[string]*dynamodb.AttributeValue, error) {
-	expr, err := expression.NewBuilder().WithKeyCondition(condition).WithFilter(filter).Build()
-	if err != nil {
-		return nil, err
-	}
-	var output []map[string]*dynamodb.AttributeValue
-	input := &dynamodb.QueryInput{
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		KeyConditionExpression:    expr.KeyCondition(),
-		FilterExpression:          expr.Filter(),
-		TableName:                 aws.String(table),
-	}
-	for {
-		result, err := client.Query(input)
-		if err != nil {
-			return nil, err
-		}
-		output = append(output, result.Items...)
-		if result.LastEvaluatedKey == nil {
-			break
-		}
-		input.ExclusiveStartKey = result.LastEvaluatedKey
-	}
-	return output, nil
+func QueryRecordWithFilter(client *dynamodb.DynamoDB, table string, condition expression.KeyConditionBuilder, filter expression.ConditionBuilder, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(context.Background(), "QueryRecordWithFilter", table, opts)
+	defer op.end(&err)
+	return query(ctx, client, table, condition, filter, QueryOptions{}, op)
 }
 
 // AddNumber func
-func AddNumber(client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, number int64) error {
+func AddNumber(client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, number int64, opts ...Option) (err error) {
+	ctx, op := startOp(context.Background(), "AddNumber", table, opts)
+	defer op.end(&err)
 	update := expression.Add(expression.Name(name), expression.Value(number))
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	expr, err := exprParts{update: &update}.build()
 	if err != nil {
 		return err
 	}
@@ -139,13 +143,18 @@ func AddNumber(client *dynamodb.DynamoDB, table string, key map[string]*dynamodb
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		Key:                       key,
+		ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
 		TableName:                 aws.String(table),
 		UpdateExpression:          expr.Update(),
 	}
-	_, err = client.UpdateItem(input)
+	op.write = true
+	result, err := client.UpdateItemWithContext(ctx, input)
 	if err != nil {
 		return err
 	}
+	op.addItems(1)
+	op.touch(key)
+	op.addCapacity(result.ConsumedCapacity)
 	return nil
 }
 
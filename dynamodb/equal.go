@@ -0,0 +1,105 @@
+package dynamodb
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// AttributeEqual reports whether a and b hold the same value the way
+// DynamoDB compares them, which reflect.DeepEqual gets wrong:
+//   - numbers compare by value, so "1", "1.0" and "1e0" are equal
+//   - sets compare as sets, ignoring order, with number members by value
+//   - lists compare element by element in order, maps attribute by attribute
+//   - values of different types are never equal, N "1" isn't S "1"
+//
+// Two nil values are equal, a nil value never equals a NULL one.
+func AttributeEqual(a, b *dynamodb.AttributeValue) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch {
+	case a.S != nil:
+		return b.S != nil && *a.S == *b.S
+	case a.N != nil:
+		return b.N != nil && numberEqual(*a.N, *b.N)
+	case a.B != nil:
+		return b.B != nil && bytes.Equal(a.B, b.B)
+	case a.BOOL != nil:
+		return b.BOOL != nil && *a.BOOL == *b.BOOL
+	case a.NULL != nil:
+		return b.NULL != nil && *a.NULL == *b.NULL
+	case a.SS != nil:
+		return b.SS != nil && setEqual(aws.StringValueSlice(a.SS), aws.StringValueSlice(b.SS), func(x, y string) bool { return x == y })
+	case a.NS != nil:
+		return b.NS != nil && setEqual(aws.StringValueSlice(a.NS), aws.StringValueSlice(b.NS), numberEqual)
+	case a.BS != nil:
+		return b.BS != nil && setEqual(a.BS, b.BS, bytes.Equal)
+	case a.L != nil:
+		if b.L == nil || len(a.L) != len(b.L) {
+			return false
+		}
+		for i := range a.L {
+			if !AttributeEqual(a.L[i], b.L[i]) {
+				return false
+			}
+		}
+		return true
+	case a.M != nil:
+		return b.M != nil && ItemsEqual(a.M, b.M)
+	}
+	return attributeType(b) == ""
+}
+
+// ItemsEqual reports whether a and b have the same attributes with values
+// equal by AttributeEqual
+func ItemsEqual(a, b map[string]*dynamodb.AttributeValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, v := range a {
+		w, ok := b[name]
+		if !ok || !AttributeEqual(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// numberEqual compares two DynamoDB numbers exactly, numbers that don't
+// parse are compared as strings
+func numberEqual(x, y string) bool {
+	a, ok := new(big.Rat).SetString(x)
+	if !ok {
+		return x == y
+	}
+	b, ok := new(big.Rat).SetString(y)
+	if !ok {
+		return false
+	}
+	return a.Cmp(b) == 0
+}
+
+// setEqual reports whether a and b have the same members, sets never hold
+// duplicates so matching every member of a once is enough
+func setEqual[T any](a, b []T, eq func(x, y T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && eq(x, y) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ErrConditionFailed is returned when the condition of a conditional write doesn't hold
+var ErrConditionFailed = errors.New("dynamodb: condition failed")
+
+// ErrTableNotFound is returned when the table doesn't exist, the error names the table
+var ErrTableNotFound = errors.New("dynamodb: table not found")
+
+// ErrIndexOutOfRange is returned when a list index is past the end of the list
+var ErrIndexOutOfRange = errors.New("dynamodb: list index out of range")
+
+// ErrUnprocessedItems is returned when a batch write still has unprocessed items after all retries
+var ErrUnprocessedItems = errors.New("dynamodb: unprocessed items")
+
+// ErrItemTooLarge is returned by an update checked with WithSizeCheck that would exceed MaxItemSize
+var ErrItemTooLarge = errors.New("dynamodb: item too large")
+
+// ErrMultipleResults is returned when a lookup expected to be unique matches several records
+var ErrMultipleResults = errors.New("dynamodb: multiple results")
+
+// ErrPageLimitExceeded is returned when a query needs more pages than WithMaxPages allows
+var ErrPageLimitExceeded = errors.New("dynamodb: page limit exceeded")
+
+// ErrIndexNotReady is returned when a global secondary index is still being created or backfilled
+var ErrIndexNotReady = errors.New("dynamodb: index not ready")
+
+// ErrDuplicateKey is returned when a batch holds two writes of the same key, see WithDuplicateKeys
+var ErrDuplicateKey = errors.New("dynamodb: duplicate key in batch")
+
+// ErrWriterClosed is returned by BatchWriter.Add after Close
+var ErrWriterClosed = errors.New("dynamodb: batch writer closed")
+
+// ItemError reports a single item that failed to unmarshal
+// Key: key attributes of the item, the whole item if they are unknown
+type ItemError struct {
+	Key map[string]*dynamodb.AttributeValue
+	Err error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("dynamodb: item %s: %v", formatKey(e.Key), e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// translateError maps AWS errors onto the package sentinels, the AWS error
+// stays reachable through errors.As
+func translateError(err error, table string) error {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	switch aerr.Code() {
+	case dynamodb.ErrCodeConditionalCheckFailedException:
+		return fmt.Errorf("%w: %w", ErrConditionFailed, err)
+	case dynamodb.ErrCodeTransactionCanceledException:
+		var canceled *dynamodb.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			for _, reason := range canceled.CancellationReasons {
+				if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+					return fmt.Errorf("%w: %w", ErrConditionFailed, err)
+				}
+			}
+		}
+	case dynamodb.ErrCodeResourceNotFoundException:
+		if table == "" {
+			return fmt.Errorf("%w: %w", ErrTableNotFound, err)
+		}
+		return fmt.Errorf("%w: %s: %w", ErrTableNotFound, table, err)
+	}
+	return err
+}
+
+// isConditionFailed reports whether err is a failed condition, translated or not
+func isConditionFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return errors.Is(err, ErrConditionFailed)
+}
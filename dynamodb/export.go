@@ -0,0 +1,209 @@
+package dynamodb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// ExportNDJSON scans table and writes every record to w as one line of
+// DynamoDB JSON, e.g. {"id":{"S":"a"},"n":{"N":"1"}}, so the types survive
+// ImportNDJSON. It returns the number of records written.
+func ExportNDJSON(ctx context.Context, client *dynamodb.DynamoDB, table string, w io.Writer, opts ...Option) (n int, err error) {
+	ctx, op := startOp(ctx, "ExportNDJSON", table, opts)
+	defer op.end(&err)
+	return exportNDJSON(ctx, client, table, w, op)
+}
+
+// ExportNDJSONGzip exports like ExportNDJSON and compresses the output with
+// gzip. The gzip stream is closed on error as well, the partial export then
+// stays readable up to the last complete record.
+func ExportNDJSONGzip(ctx context.Context, client *dynamodb.DynamoDB, table string, w io.Writer, opts ...Option) (n int, err error) {
+	ctx, op := startOp(ctx, "ExportNDJSONGzip", table, opts)
+	defer op.end(&err)
+	zw := gzip.NewWriter(w)
+	n, err = exportNDJSON(ctx, client, table, zw, op)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+func exportNDJSON(ctx context.Context, client *dynamodb.DynamoDB, table string, w io.Writer, op *operation) (int, error) {
+	input, err := scanInput(table, expression.ConditionBuilder{}, op.opts)
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	n := 0
+	for {
+		if err := op.nextPage(); err != nil {
+			bw.Flush()
+			return n, err
+		}
+		result, err := client.ScanWithContext(ctx, input)
+		if err != nil {
+			bw.Flush()
+			return n, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		for _, item := range result.Items {
+			if err := encoder.Encode(encodeItem(item)); err != nil {
+				return n, err
+			}
+			n++
+		}
+		op.addItems(len(result.Items))
+		if result.LastEvaluatedKey == nil {
+			return n, bw.Flush()
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ImportNDJSON writes the records read from r, as written by ExportNDJSON, in
+// batches of 25 and returns the number of records written
+func ImportNDJSON(ctx context.Context, client *dynamodb.DynamoDB, table string, r io.Reader, opts ...Option) (n int, err error) {
+	ctx, op := startOp(ctx, "ImportNDJSON", table, opts)
+	defer op.end(&err)
+	return importNDJSON(ctx, client, table, r, op)
+}
+
+// ImportNDJSONGzip imports like ImportNDJSON from the gzip compressed output
+// of ExportNDJSONGzip
+func ImportNDJSONGzip(ctx context.Context, client *dynamodb.DynamoDB, table string, r io.Reader, opts ...Option) (n int, err error) {
+	ctx, op := startOp(ctx, "ImportNDJSONGzip", table, opts)
+	defer op.end(&err)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+	return importNDJSON(ctx, client, table, zr, op)
+}
+
+func importNDJSON(ctx context.Context, client *dynamodb.DynamoDB, table string, r io.Reader, op *operation) (int, error) {
+	decoder := json.NewDecoder(r)
+	n := 0
+	requests := make([]*dynamodb.WriteRequest, 0, MaxBatchWriteItems)
+	for {
+		var item map[string]*dynamodb.AttributeValue
+		err := decoder.Decode(&item)
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+		if err == nil {
+			requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+		}
+		if len(requests) == MaxBatchWriteItems || (err == io.EOF && len(requests) > 0) {
+			if err := batchWrite(ctx, client, table, requests, op); err != nil {
+				return n, err
+			}
+			n += len(requests)
+			requests = make([]*dynamodb.WriteRequest, 0, MaxBatchWriteItems)
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+	}
+}
+
+// CanonicalJSON returns item as DynamoDB JSON with sorted attribute names and
+// set members, so equal items always give the same bytes for hashing and
+// change detection. Numbers are kept as stored, 1.0 differs from 1.
+func CanonicalJSON(item map[string]*dynamodb.AttributeValue) ([]byte, error) {
+	m := make(map[string]interface{}, len(item))
+	for name, v := range item {
+		m[name] = canonicalValue(v)
+	}
+	return json.Marshal(m)
+}
+
+// canonicalValue encodes v like encodeValue with sorted set members
+func canonicalValue(v *dynamodb.AttributeValue) interface{} {
+	switch {
+	case v == nil:
+		return encodeValue(v)
+	case v.SS != nil:
+		return map[string][]string{"SS": sortedStrings(v.SS)}
+	case v.NS != nil:
+		return map[string][]string{"NS": sortedStrings(v.NS)}
+	case v.BS != nil:
+		bs := append([][]byte(nil), v.BS...)
+		sort.Slice(bs, func(i, j int) bool { return bytes.Compare(bs[i], bs[j]) < 0 })
+		return map[string][][]byte{"BS": bs}
+	case v.L != nil:
+		l := make([]interface{}, len(v.L))
+		for i, e := range v.L {
+			l[i] = canonicalValue(e)
+		}
+		return map[string]interface{}{"L": l}
+	case v.M != nil:
+		m := make(map[string]interface{}, len(v.M))
+		for name, e := range v.M {
+			m[name] = canonicalValue(e)
+		}
+		return map[string]interface{}{"M": m}
+	}
+	return encodeValue(v)
+}
+
+func sortedStrings(ss []*string) []string {
+	output := make([]string, len(ss))
+	for i, s := range ss {
+		output[i] = aws.StringValue(s)
+	}
+	sort.Strings(output)
+	return output
+}
+
+// encodeItem returns item in DynamoDB JSON, leaving out the unset fields
+// json.Marshal would write as null
+func encodeItem(item map[string]*dynamodb.AttributeValue) map[string]interface{} {
+	output := make(map[string]interface{}, len(item))
+	for name, v := range item {
+		output[name] = encodeValue(v)
+	}
+	return output
+}
+
+func encodeValue(v *dynamodb.AttributeValue) interface{} {
+	switch {
+	case v == nil:
+		return map[string]bool{"NULL": true}
+	case v.S != nil:
+		return map[string]string{"S": *v.S}
+	case v.N != nil:
+		return map[string]string{"N": *v.N}
+	case v.B != nil:
+		return map[string][]byte{"B": v.B}
+	case v.BOOL != nil:
+		return map[string]bool{"BOOL": *v.BOOL}
+	case v.NULL != nil:
+		return map[string]bool{"NULL": *v.NULL}
+	case v.SS != nil:
+		return map[string][]*string{"SS": v.SS}
+	case v.NS != nil:
+		return map[string][]*string{"NS": v.NS}
+	case v.BS != nil:
+		return map[string][][]byte{"BS": v.BS}
+	case v.L != nil:
+		l := make([]interface{}, len(v.L))
+		for i, e := range v.L {
+			l[i] = encodeValue(e)
+		}
+		return map[string]interface{}{"L": l}
+	case v.M != nil:
+		return map[string]interface{}{"M": encodeItem(v.M)}
+	}
+	return map[string]interface{}{}
+}
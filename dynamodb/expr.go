@@ -0,0 +1,90 @@
+package dynamodb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// ExpressionError reports which part of an expression failed to build
+// Part: key condition, condition, filter, update or projection
+type ExpressionError struct {
+	Part string
+	Err  error
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("dynamodb: invalid %s expression: %v", e.Part, e.Err)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// exprParts are the parts of an expression, nil parts are left out
+type exprParts struct {
+	keyCondition *expression.KeyConditionBuilder
+	condition    *expression.ConditionBuilder
+	filter       *expression.ConditionBuilder
+	update       *expression.UpdateBuilder
+	projection   *expression.ProjectionBuilder
+}
+
+// builders returns one builder per part in a fixed order
+func (p exprParts) builders() ([]string, []expression.Builder) {
+	var names []string
+	var builders []expression.Builder
+	if p.keyCondition != nil {
+		names = append(names, "key condition")
+		builders = append(builders, expression.NewBuilder().WithKeyCondition(*p.keyCondition))
+	}
+	if p.condition != nil {
+		names = append(names, "condition")
+		builders = append(builders, expression.NewBuilder().WithCondition(*p.condition))
+	}
+	if p.filter != nil {
+		names = append(names, "filter")
+		builders = append(builders, expression.NewBuilder().WithFilter(*p.filter))
+	}
+	if p.update != nil {
+		names = append(names, "update")
+		builders = append(builders, expression.NewBuilder().WithUpdate(*p.update))
+	}
+	if p.projection != nil {
+		names = append(names, "projection")
+		builders = append(builders, expression.NewBuilder().WithProjection(*p.projection))
+	}
+	return names, builders
+}
+
+// build builds the expression, a failure is reported as *ExpressionError
+// naming the first part that doesn't build on its own
+func (p exprParts) build() (expression.Expression, error) {
+	builder := expression.NewBuilder()
+	if p.keyCondition != nil {
+		builder = builder.WithKeyCondition(*p.keyCondition)
+	}
+	if p.condition != nil {
+		builder = builder.WithCondition(*p.condition)
+	}
+	if p.filter != nil {
+		builder = builder.WithFilter(*p.filter)
+	}
+	if p.update != nil {
+		builder = builder.WithUpdate(*p.update)
+	}
+	if p.projection != nil {
+		builder = builder.WithProjection(*p.projection)
+	}
+	expr, err := builder.Build()
+	if err == nil {
+		return expr, nil
+	}
+	names, builders := p.builders()
+	for i, b := range builders {
+		if _, partErr := b.Build(); partErr != nil {
+			return expr, &ExpressionError{Part: names[i], Err: partErr}
+		}
+	}
+	return expr, &ExpressionError{Part: "combined", Err: err}
+}
@@ -0,0 +1,91 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// testReservedWords are DynamoDB reserved words commonly used as attribute names
+var testReservedWords = []string{"name", "status", "type", "timestamp"}
+
+// checkPlaceholders fails if an expression names a reserved word literally
+// instead of through a # placeholder, or if the placeholders miss one
+func checkPlaceholders(t *testing.T, part string, names map[string]*string, exprs ...*string) {
+	t.Helper()
+	literal := regexp.MustCompile(`(^|[^#:\w])(name|status|type|timestamp)\b`)
+	for _, e := range exprs {
+		if e == nil {
+			t.Errorf("%s: missing expression", part)
+			continue
+		}
+		if literal.MatchString(*e) {
+			t.Errorf("%s: reserved word used literally in %q", part, *e)
+		}
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[aws.StringValue(name)] = true
+	}
+	for _, word := range testReservedWords {
+		if !seen[word] {
+			t.Errorf("%s: %s has no placeholder in %v", part, word, aws.StringValueMap(names))
+		}
+	}
+}
+
+// testReservedFilter is a condition on every reserved word
+var testReservedFilter = expression.Name("status").Equal(expression.Value("active")).
+	And(expression.Name("type").Equal(expression.Value("a"))).
+	And(expression.Name("timestamp").GreaterThan(expression.Value(1))).
+	And(expression.Name("name").AttributeExists())
+
+func TestQueryInputReservedWords(t *testing.T) {
+	keyCond := expression.Key("name").Equal(expression.Value("a")).And(expression.Key("timestamp").GreaterThan(expression.Value(1)))
+	filter := expression.Name("status").Equal(expression.Value("active")).And(expression.Name("type").Equal(expression.Value("a")))
+	input, err := queryInput("t", keyCond, filter, QueryOptions{ProjectionAttrs: testReservedWords}, newOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlaceholders(t, "query", input.ExpressionAttributeNames, input.KeyConditionExpression, input.FilterExpression, input.ProjectionExpression)
+}
+
+func TestScanInputReservedWords(t *testing.T) {
+	input, err := scanInput("t", testReservedFilter, newOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlaceholders(t, "scan", input.ExpressionAttributeNames, input.FilterExpression)
+}
+
+func TestExprPartsReservedWords(t *testing.T) {
+	update := expression.Set(expression.Name("status"), expression.Value("done")).
+		Set(expression.Name("type"), expression.Value("b")).
+		Set(expression.Name("timestamp"), expression.Value(2)).
+		Remove(expression.Name("name"))
+	condition := testReservedFilter
+	expr, err := exprParts{update: &update, condition: &condition}.build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlaceholders(t, "update", expr.Names(), expr.Update(), expr.Condition())
+}
+
+func TestQueryRecordsReservedWordKey(t *testing.T) {
+	var input dynamodb.QueryInput
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Error(err)
+		}
+		return http.StatusOK, dynamodb.QueryOutput{}
+	})
+	if _, err := QueryRecords(client, "t", "", "name", "a", testReservedFilter); err != nil {
+		t.Fatal(err)
+	}
+	checkPlaceholders(t, "QueryRecords", input.ExpressionAttributeNames, input.KeyConditionExpression, input.FilterExpression)
+}
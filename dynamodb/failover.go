@@ -0,0 +1,108 @@
+package dynamodb
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Failover runs helper calls against the primary client of a global table
+// and, once the primary failed Threshold times in a row, sends reads to the
+// secondary client in another region for Cooldown before trying the primary
+// again. Writes stay on the primary unless WriteFailover is set, replication
+// between regions is asynchronous so reads from the secondary can be stale
+// and writes to both regions conflict as last writer wins.
+type Failover struct {
+	Primary   *dynamodb.DynamoDB
+	Secondary *dynamodb.DynamoDB
+	// Threshold is the number of consecutive failures of the primary that
+	// fail over, 3 by default
+	Threshold int
+	// Cooldown is how long calls stay on the secondary, 30s by default
+	Cooldown time.Duration
+	// WriteFailover sends writes to the secondary too while failed over
+	WriteFailover bool
+
+	mu       sync.Mutex
+	failures int
+	until    time.Time
+}
+
+// NewFailover func returns a Failover with the default Threshold and Cooldown
+func NewFailover(primary, secondary *dynamodb.DynamoDB) *Failover {
+	return &Failover{Primary: primary, Secondary: secondary, Threshold: 3, Cooldown: 30 * time.Second}
+}
+
+// Read calls fn with the client reads currently go to. A failure of the
+// primary that crosses Threshold is retried once on the secondary.
+func (f *Failover) Read(fn func(client *dynamodb.DynamoDB) error) error {
+	return f.run(fn, true)
+}
+
+// Write calls fn with the primary, or with the secondary while failed over
+// if WriteFailover is set
+func (f *Failover) Write(fn func(client *dynamodb.DynamoDB) error) error {
+	return f.run(fn, f.WriteFailover)
+}
+
+// FailedOver reports whether calls currently go to the secondary
+func (f *Failover) FailedOver() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().Before(f.until)
+}
+
+func (f *Failover) run(fn func(client *dynamodb.DynamoDB) error, failover bool) error {
+	if failover && f.FailedOver() {
+		return fn(f.Secondary)
+	}
+	err := fn(f.Primary)
+	if !f.observe(err) || !failover {
+		return err
+	}
+	return fn(f.Secondary)
+}
+
+// observe counts a call of the primary and reports whether it failed over
+func (f *Failover) observe(err error) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !isRegionFailure(err) {
+		f.failures = 0
+		return false
+	}
+	f.failures++
+	threshold := f.Threshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	if f.failures < threshold {
+		return false
+	}
+	cooldown := f.Cooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	f.failures = 0
+	f.until = time.Now().Add(cooldown)
+	return true
+}
+
+// isRegionFailure reports whether err means the region may be unavailable,
+// rather than the request being wrong or throttled, which the secondary
+// replicating the same writes wouldn't fix
+func isRegionFailure(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case dynamodb.ErrCodeInternalServerError, "ServiceUnavailable", request.ErrCodeRequestError, request.ErrCodeResponseTimeout:
+		return true
+	}
+	return false
+}
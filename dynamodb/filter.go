@@ -0,0 +1,50 @@
+package dynamodb
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// WhereExists matches records having attr, e.g. to query the items of a
+// sparse attribute
+func WhereExists(attr string) expression.ConditionBuilder {
+	return expression.Name(attr).AttributeExists()
+}
+
+// WhereNotExists matches records without attr
+func WhereNotExists(attr string) expression.ConditionBuilder {
+	return expression.Name(attr).AttributeNotExists()
+}
+
+// MissingOr matches records without attr or satisfying condition, for filters
+// on attributes only some records have. A plain comparison on a missing
+// attribute is false, so such records would be filtered out.
+func MissingOr(attr string, condition expression.ConditionBuilder) expression.ConditionBuilder {
+	return WhereNotExists(attr).Or(condition)
+}
+
+// All combines conditions with AND, unset conditions are skipped and none
+// gives an unset condition which the helpers treat as no filter
+func All(conditions ...expression.ConditionBuilder) expression.ConditionBuilder {
+	return combine(conditions, expression.And)
+}
+
+// Any combines conditions with OR like All
+func Any(conditions ...expression.ConditionBuilder) expression.ConditionBuilder {
+	return combine(conditions, expression.Or)
+}
+
+func combine(conditions []expression.ConditionBuilder, join func(left, right expression.ConditionBuilder, other ...expression.ConditionBuilder) expression.ConditionBuilder) expression.ConditionBuilder {
+	var set []expression.ConditionBuilder
+	for _, c := range conditions {
+		if isSet(c) {
+			set = append(set, c)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return expression.ConditionBuilder{}
+	case 1:
+		return set[0]
+	}
+	return join(set[0], set[1], set[2:]...)
+}
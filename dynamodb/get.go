@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// GetRecord returns the record with the given key, nil if it doesn't exist.
+// WithStrongFallback retries a miss once with a strongly consistent read.
+// key: DynamoDB key of the record
+func GetRecord(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "GetRecord", table, opts)
+	defer op.end(&err)
+	return getRecord(ctx, client, table, key, op)
+}
+
+// Get fetches the record with the given key like GetRecord and unmarshals it
+// into T, found is false without an error when the record doesn't exist
+func Get[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, opts ...Option) (value T, found bool, err error) {
+	ctx, op := startOp(ctx, "Get", table, opts)
+	defer op.end(&err)
+	item, err := getRecord(ctx, client, table, key, op)
+	if err != nil || item == nil {
+		return value, false, err
+	}
+	if err := unmarshalItem(item, &value, op.opts.Coercions); err != nil {
+		return value, false, &ItemError{Key: key, Err: err}
+	}
+	return value, true, nil
+}
+
+// getRecord reads the record and retries a miss with a consistent read when
+// StrongFallback is set
+func getRecord(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, op *operation) (map[string]*dynamodb.AttributeValue, error) {
+	item, err := getItem(ctx, client, table, key, op.opts.ConsistentRead, op)
+	if err != nil || item != nil || op.opts.ConsistentRead || !op.opts.StrongFallback {
+		return item, err
+	}
+	op.opts.logf("dynamodb: record missing on %s, retrying with a consistent read", table)
+	return getItem(ctx, client, table, key, true, op)
+}
+
+func getItem(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, consistent bool, op *operation) (map[string]*dynamodb.AttributeValue, error) {
+	input := &dynamodb.GetItemInput{
+		Key:                    key,
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	if consistent {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	result, err := client.GetItemWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	op.addCapacity(result.ConsumedCapacity)
+	op.touch(key)
+	if result.Item == nil {
+		return nil, nil
+	}
+	op.addItems(1)
+	return result.Item, nil
+}
@@ -0,0 +1,104 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// testStatus is an enum stored as its name
+type testStatus int
+
+const (
+	testActive testStatus = iota + 1
+	testSuspended
+)
+
+var testStatusNames = map[testStatus]string{testActive: "active", testSuspended: "suspended"}
+
+func (s testStatus) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	name, ok := testStatusNames[s]
+	if !ok {
+		return fmt.Errorf("unknown status %d", s)
+	}
+	av.S = aws.String(name)
+	return nil
+}
+
+func (s *testStatus) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	for status, name := range testStatusNames {
+		if name == aws.StringValue(av.S) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown status %v", av)
+}
+
+type testAccount struct {
+	ID      string       `dynamodbav:"id"`
+	Status  testStatus   `dynamodbav:"status"`
+	History []testStatus `dynamodbav:"history"`
+}
+
+// fakeTable stores the item of the last PutItem and serves it to GetItem and
+// Scan requests
+func fakeTable(t *testing.T) fakeHandler {
+	var item map[string]*dynamodb.AttributeValue
+	return func(op string, body []byte) (int, interface{}) {
+		switch op {
+		case "PutItem":
+			var input dynamodb.PutItemInput
+			if err := json.Unmarshal(body, &input); err != nil {
+				t.Error(err)
+			}
+			item = input.Item
+			return http.StatusOK, dynamodb.PutItemOutput{}
+		case "GetItem":
+			return http.StatusOK, dynamodb.GetItemOutput{Item: item}
+		case "Scan":
+			return http.StatusOK, dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}
+		}
+		return http.StatusBadRequest, fakeError("UnknownOperationException")
+	}
+}
+
+func TestCustomMarshalerRoundTrip(t *testing.T) {
+	in := testAccount{ID: "a", Status: testSuspended, History: []testStatus{testActive, testSuspended}}
+	item, err := MarshalItem(in, MarshalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.StringValue(item["status"].S); got != "suspended" {
+		t.Errorf("MarshalItem: got status %q, want suspended", got)
+	}
+	if got := aws.StringValue(item["history"].L[0].S); got != "active" {
+		t.Errorf("MarshalItem: got history[0] %q, want active", got)
+	}
+
+	ctx := context.Background()
+	client := newFakeClient(t, fakeTable(t))
+	if err := Put(ctx, client, "t", in); err != nil {
+		t.Fatal(err)
+	}
+	out, found, err := Get[testAccount](ctx, client, "t", map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}})
+	if err != nil || !found {
+		t.Fatalf("Get: got found %v, err %v", found, err)
+	}
+	if out.Status != in.Status || len(out.History) != 2 || out.History[1] != testSuspended {
+		t.Errorf("Get: got %+v, want %+v", out, in)
+	}
+	scanned, err := ScanTyped[testAccount](ctx, client, "t", expression.ConditionBuilder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scanned) != 1 || scanned[0].Status != in.Status {
+		t.Errorf("ScanTyped: got %+v, want [%+v]", scanned, in)
+	}
+}
@@ -1,5 +1,12 @@
 module github.com/saidmu/acloud/dynamodb
 
-go 1.14
+go 1.20
 
-require github.com/aws/aws-sdk-go v1.33.11
+require (
+	github.com/aws/aws-sdk-go v1.55.8
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
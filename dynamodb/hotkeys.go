@@ -0,0 +1,84 @@
+package dynamodb
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// KeyCount is the number of operations that hit one partition key value
+type KeyCount struct {
+	Table string
+	Key   string
+	Count int64
+}
+
+// HotKeyTracker tallies client side how many operations hit each partition
+// key value, to diagnose throttling caused by hot partitions. Scans are not
+// counted since they don't target a partition.
+type HotKeyTracker struct {
+	mu            sync.Mutex
+	partitionKeys map[string]string
+	counts        map[[2]string]int64
+}
+
+// NewHotKeyTracker func returns an empty tracker
+// partitionKeys: partition key attribute name per table name, other tables are ignored
+func NewHotKeyTracker(partitionKeys map[string]string) *HotKeyTracker {
+	return &HotKeyTracker{partitionKeys: partitionKeys, counts: map[[2]string]int64{}}
+}
+
+// Observe counts one operation on the partition of item
+func (t *HotKeyTracker) Observe(table string, item map[string]*dynamodb.AttributeValue) {
+	name, ok := t.partitionKeys[table]
+	if !ok {
+		return
+	}
+	v, ok := item[name]
+	if !ok {
+		return
+	}
+	key := [2]string{table, formatValue(v)}
+	t.mu.Lock()
+	t.counts[key]++
+	t.mu.Unlock()
+}
+
+// Top returns the n hottest partition key values, hottest first
+func (t *HotKeyTracker) Top(n int) []KeyCount {
+	t.mu.Lock()
+	output := make([]KeyCount, 0, len(t.counts))
+	for k, c := range t.counts {
+		output = append(output, KeyCount{Table: k[0], Key: k[1], Count: c})
+	}
+	t.mu.Unlock()
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Count != output[j].Count {
+			return output[i].Count > output[j].Count
+		}
+		if output[i].Table != output[j].Table {
+			return output[i].Table < output[j].Table
+		}
+		return output[i].Key < output[j].Key
+	})
+	if n >= 0 && n < len(output) {
+		output = output[:n]
+	}
+	return output
+}
+
+// Reset clears all counts
+func (t *HotKeyTracker) Reset() {
+	t.mu.Lock()
+	t.counts = map[[2]string]int64{}
+	t.mu.Unlock()
+}
+
+var hotKeys *HotKeyTracker
+
+// SetHotKeyTracker attaches t to all helpers, nil detaches it.
+// It should be called before the helpers are used.
+func SetHotKeyTracker(t *HotKeyTracker) {
+	hotKeys = t
+}
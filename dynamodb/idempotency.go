@@ -0,0 +1,50 @@
+package dynamodb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IdempotencyKey returns the hex SHA-256 of the attrs of item, in the given
+// order, so the same logical record always maps to the same key. Values are
+// hashed in the form of CanonicalJSON, the order of set members doesn't
+// matter but the number 1.0 differs from 1. Reads can compute the key the
+// same way from the source attributes.
+func IdempotencyKey(item map[string]*dynamodb.AttributeValue, attrs ...string) (string, error) {
+	if len(attrs) == 0 {
+		return "", fmt.Errorf("dynamodb: idempotency key needs at least one attribute")
+	}
+	h := sha256.New()
+	for _, attr := range attrs {
+		v, ok := item[attr]
+		if !ok || v == nil {
+			return "", fmt.Errorf("dynamodb: record has no %q attribute", attr)
+		}
+		encoded, err := json.Marshal(canonicalValue(v))
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(attr))
+		h.Write([]byte{0})
+		h.Write(encoded)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SetIdempotencyKey stores the IdempotencyKey of attrs in keyAttr, e.g. the
+// partition key, so writing the same logical record again overwrites it
+// instead of adding a duplicate
+func SetIdempotencyKey(item map[string]*dynamodb.AttributeValue, keyAttr string, attrs ...string) error {
+	key, err := IdempotencyKey(item, attrs...)
+	if err != nil {
+		return err
+	}
+	item[keyAttr] = &dynamodb.AttributeValue{S: aws.String(key)}
+	return nil
+}
@@ -0,0 +1,68 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IndexReady returns ErrIndexNotReady, naming the index status, unless the
+// global secondary index is ACTIVE or UPDATING and done backfilling. A query
+// on an index still backfilling misses the records not indexed yet instead
+// of failing, so check it right after the index is created.
+// index: DynamoDB index name
+func IndexReady(ctx context.Context, client *dynamodb.DynamoDB, table, index string, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "IndexReady", table, opts)
+	defer op.end(&err)
+	return indexReady(ctx, client, table, index)
+}
+
+// defaultIndexPoll is the interval of WaitIndexReady when none is given
+const defaultIndexPoll = 5 * time.Second
+
+// WaitIndexReady polls the index every interval until IndexReady succeeds or
+// ctx is done
+// interval: 0 or less polls every 5 seconds
+func WaitIndexReady(ctx context.Context, client *dynamodb.DynamoDB, table, index string, interval time.Duration, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WaitIndexReady", table, opts)
+	defer op.end(&err)
+	if interval <= 0 {
+		interval = defaultIndexPoll
+	}
+	for {
+		err := indexReady(ctx, client, table, index)
+		if !errors.Is(err, ErrIndexNotReady) {
+			return err
+		}
+		op.retries++
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+func indexReady(ctx context.Context, client *dynamodb.DynamoDB, table, index string) error {
+	result, err := client.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return err
+	}
+	for _, gsi := range result.Table.GlobalSecondaryIndexes {
+		if aws.StringValue(gsi.IndexName) != index {
+			continue
+		}
+		status := aws.StringValue(gsi.IndexStatus)
+		// UPDATING only changes throughput, the index serves queries
+		if status != dynamodb.IndexStatusActive && status != dynamodb.IndexStatusUpdating {
+			return fmt.Errorf("%w: %s is %s", ErrIndexNotReady, index, status)
+		}
+		if aws.BoolValue(gsi.Backfilling) {
+			return fmt.Errorf("%w: %s is backfilling", ErrIndexNotReady, index)
+		}
+		return nil
+	}
+	return fmt.Errorf("dynamodb: no global secondary index %s on %s", index, table)
+}
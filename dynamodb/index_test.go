@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// fakeIndexStatuses answers DescribeTable with the index gsi1 in the given
+// statuses, one per request, the last one repeated
+func fakeIndexStatuses(statuses ...string) fakeHandler {
+	return func(op string, body []byte) (int, interface{}) {
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		return http.StatusOK, dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{{IndexName: aws.String("gsi1"), IndexStatus: aws.String(status)}},
+		}}
+	}
+}
+
+func TestIndexReady(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(t, fakeIndexStatuses(dynamodb.IndexStatusCreating, dynamodb.IndexStatusActive))
+	var summary Summary
+	if err := IndexReady(ctx, client, "t", "gsi1", WithSummary(&summary)); !errors.Is(err, ErrIndexNotReady) {
+		t.Errorf("got %v, want ErrIndexNotReady", err)
+	}
+	if summary.Operation != "IndexReady" || summary.Err == nil {
+		t.Errorf("got summary %+v", summary)
+	}
+	if err := IndexReady(ctx, client, "t", "gsi1"); err != nil {
+		t.Errorf("got %v once ACTIVE", err)
+	}
+}
+
+func TestWaitIndexReady(t *testing.T) {
+	client := newFakeClient(t, fakeIndexStatuses(dynamodb.IndexStatusCreating, dynamodb.IndexStatusCreating, dynamodb.IndexStatusActive))
+	var summary Summary
+	if err := WaitIndexReady(context.Background(), client, "t", "gsi1", time.Millisecond, WithSummary(&summary)); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Retries != 2 {
+		t.Errorf("got %d polls retried, want 2", summary.Retries)
+	}
+	// without an interval the first poll waits defaultIndexPoll
+	client = newFakeClient(t, fakeIndexStatuses(dynamodb.IndexStatusCreating))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	requests := 0
+	client.Handlers.Send.PushFront(func(r *request.Request) { requests++ })
+	if err := WaitIndexReady(ctx, client, "t", "gsi1", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the context deadline", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d polls within 50ms, want 1", requests)
+	}
+}
@@ -0,0 +1,86 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// PluckStrings returns the string value of attr of each item, items missing
+// the attribute or holding another type are skipped
+func PluckStrings(items []map[string]*dynamodb.AttributeValue, attr string) []string {
+	var output []string
+	for _, item := range items {
+		if v, ok := item[attr]; ok && v != nil && v.S != nil {
+			output = append(output, *v.S)
+		}
+	}
+	return output
+}
+
+// PluckFloat64s returns the number value of attr of each item, items missing
+// the attribute or holding another type are skipped
+func PluckFloat64s(items []map[string]*dynamodb.AttributeValue, attr string) []float64 {
+	var output []float64
+	for _, item := range items {
+		if v, ok := item[attr]; ok && v != nil && v.N != nil {
+			if n, err := strconv.ParseFloat(*v.N, 64); err == nil {
+				output = append(output, n)
+			}
+		}
+	}
+	return output
+}
+
+// PluckInt64s returns the number value of attr of each item, items missing
+// the attribute or holding another type or a non-integer are skipped
+func PluckInt64s(items []map[string]*dynamodb.AttributeValue, attr string) []int64 {
+	var output []int64
+	for _, item := range items {
+		if v, ok := item[attr]; ok && v != nil && v.N != nil {
+			if n, err := strconv.ParseInt(*v.N, 10, 64); err == nil {
+				output = append(output, n)
+			}
+		}
+	}
+	return output
+}
+
+// ToMaps unmarshals items into plain maps for logging, JSON or dynamic
+// processing. Numbers become float64, sets become slices.
+func ToMaps(items []map[string]*dynamodb.AttributeValue) ([]map[string]interface{}, error) {
+	output := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		var m map[string]interface{}
+		if err := dynamodbattribute.UnmarshalMap(item, &m); err != nil {
+			return nil, &ItemError{Key: item, Err: err}
+		}
+		output = append(output, m)
+	}
+	return output, nil
+}
+
+// QueryMaps returns the records matching keyCond and filter like Query, as
+// plain maps, see ToMaps
+func QueryMaps(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) (_ []map[string]interface{}, err error) {
+	ctx, op := startOp(ctx, "QueryMaps", table, opts)
+	defer op.end(&err)
+	items, err := query(ctx, client, table, keyCond, filter, q, op)
+	if err != nil {
+		return nil, err
+	}
+	return ToMaps(items)
+}
+
+// ScanMaps returns every record matching filter like ScanRecords, as plain
+// maps, see ToMaps
+func ScanMaps(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, opts ...Option) ([]map[string]interface{}, error) {
+	items, err := ScanRecords(ctx, client, table, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ToMaps(items)
+}
@@ -0,0 +1,21 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestToMapsItemErrorKey(t *testing.T) {
+	bad := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("b")}, "n": {N: aws.String("not a number")}}
+	_, err := ToMaps([]map[string]*dynamodb.AttributeValue{{"id": {S: aws.String("a")}}, bad})
+	var ierr *ItemError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("got %v, want *ItemError", err)
+	}
+	if KeyString(ierr.Key) != KeyString(bad) {
+		t.Errorf("got key %s, want the failing item %s", KeyString(ierr.Key), KeyString(bad))
+	}
+}
@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// KeyAttr is one attribute of a DynamoDB key
+type KeyAttr struct {
+	Name  string
+	Value interface{}
+}
+
+// BuildKey marshals the key attributes with dynamodbattribute.Marshal so
+// numbers become N and strings S, instead of hand-built AttributeValues with
+// the wrong type. A key has one or two attributes of type string, number or binary.
+func BuildKey(pairs ...KeyAttr) (map[string]*dynamodb.AttributeValue, error) {
+	if len(pairs) == 0 || len(pairs) > 2 {
+		return nil, fmt.Errorf("dynamodb: a key has 1 or 2 attributes, got %d", len(pairs))
+	}
+	key := make(map[string]*dynamodb.AttributeValue, len(pairs))
+	for _, pair := range pairs {
+		if pair.Name == "" {
+			return nil, fmt.Errorf("dynamodb: key attribute has an empty name")
+		}
+		if _, ok := key[pair.Name]; ok {
+			return nil, fmt.Errorf("dynamodb: key attribute %q is repeated", pair.Name)
+		}
+		v, err := dynamodbattribute.Marshal(pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		if v.S == nil && v.N == nil && v.B == nil {
+			return nil, fmt.Errorf("dynamodb: key attribute %q must be a string, number or binary", pair.Name)
+		}
+		key[pair.Name] = v
+	}
+	return key, nil
+}
+
+// CompositeKey composes sort keys such as TYPE#id#subtype from ordered parts
+// and splits them back. A separator or backslash inside a part is escaped with
+// a backslash, so parts containing the separator can't collide.
+type CompositeKey struct {
+	// Separator between parts, "#" when empty
+	Separator string
+}
+
+func (c CompositeKey) separator() string {
+	if c.Separator == "" {
+		return "#"
+	}
+	return c.Separator
+}
+
+// Join composes the key from parts
+func (c CompositeKey) Join(parts ...string) string {
+	sep := c.separator()
+	escaper := strings.NewReplacer(`\`, `\\`, sep, `\`+sep)
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = escaper.Replace(part)
+	}
+	return strings.Join(escaped, sep)
+}
+
+// Split returns the parts of a key composed by Join
+func (c CompositeKey) Split(key string) []string {
+	sep := c.separator()
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\' && i+1 < len(key):
+			i++
+			if strings.HasPrefix(key[i:], sep) {
+				part.WriteString(sep)
+				i += len(sep)
+			} else {
+				part.WriteByte(key[i])
+				i++
+			}
+		case strings.HasPrefix(key[i:], sep):
+			parts = append(parts, part.String())
+			part.Reset()
+			i += len(sep)
+		default:
+			part.WriteByte(key[i])
+			i++
+		}
+	}
+	return append(parts, part.String())
+}
+
+// Parse splits the composite key stored in attr of item
+func (c CompositeKey) Parse(item map[string]*dynamodb.AttributeValue, attr string) ([]string, error) {
+	v, ok := item[attr]
+	if !ok || v == nil || v.S == nil {
+		return nil, fmt.Errorf("dynamodb: item has no string attribute %q", attr)
+	}
+	return c.Split(*v.S), nil
+}
+
+// WriteRecordWithCompositeKey composes attr from parts with ck, sets it on the
+// record and writes the record like WriteRecord
+func WriteRecordWithCompositeKey(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table, attr string, ck CompositeKey, parts []string, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordWithCompositeKey", table, opts)
+	defer op.end(&err)
+	item, err := data.Payload()
+	if err != nil {
+		return err
+	}
+	item[attr] = &dynamodb.AttributeValue{S: aws.String(ck.Join(parts...))}
+	if err := validate(item); err != nil {
+		return err
+	}
+	input := &dynamodb.PutItemInput{
+		Item:                   item,
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	op.write = true
+	result, err := client.PutItemWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	op.addItems(1)
+	op.touch(item)
+	op.addCapacity(result.ConsumedCapacity)
+	return nil
+}
@@ -0,0 +1,101 @@
+package dynamodb
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// MarshalOptions controls which attribute values MarshalItem drops
+type MarshalOptions struct {
+	// SkipEmptyStrings drops "" attributes. Tables that accept empty strings
+	// can leave it off to store them as is.
+	SkipEmptyStrings bool
+	// SkipEmptyCollections drops empty lists, maps and sets.
+	// DynamoDB always rejects empty sets.
+	SkipEmptyCollections bool
+	// SkipNulls drops NULL attributes, e.g. nil pointers
+	SkipNulls bool
+}
+
+// MarshalItem marshals a struct or map into a DynamoDB item and drops the
+// attribute values selected by opts, so writes don't fail on them.
+//
+// Nested structs and slices of structs become maps and lists honoring their
+// dynamodbav tags, and round-trip through dynamodbattribute.UnmarshalMap.
+// Pruning also applies to the members of nested maps, including maps inside
+// lists, but list elements themselves are never dropped so indexes are kept.
+// A skipped attribute unmarshals to the zero value of its field.
+//
+// Numeric collections keep their Go shape: map[string]int and
+// map[string]float64 become a Map of Numbers and []int a List of Numbers.
+// Only a field tagged dynamodbav:",numberset" becomes a Number Set, which
+// loses order and duplicates.
+func MarshalItem(v interface{}, opts MarshalOptions) (map[string]*dynamodb.AttributeValue, error) {
+	encoder := dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+		e.NullEmptyString = false
+		e.EnableEmptyCollections = true
+	})
+	av, err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	item := av.M
+	if item == nil {
+		item = map[string]*dynamodb.AttributeValue{}
+	}
+	pruneItem(item, opts)
+	return item, nil
+}
+
+func pruneItem(item map[string]*dynamodb.AttributeValue, opts MarshalOptions) {
+	for k, v := range item {
+		pruneValue(v, opts)
+		if skipValue(v, opts) {
+			delete(item, k)
+		}
+	}
+}
+
+func pruneValue(v *dynamodb.AttributeValue, opts MarshalOptions) {
+	if v == nil {
+		return
+	}
+	if v.M != nil {
+		pruneItem(v.M, opts)
+	}
+	for _, e := range v.L {
+		pruneValue(e, opts)
+	}
+}
+
+func skipValue(v *dynamodb.AttributeValue, opts MarshalOptions) bool {
+	switch {
+	case v == nil:
+		return opts.SkipNulls
+	case v.NULL != nil && *v.NULL:
+		return opts.SkipNulls
+	case v.S != nil:
+		return opts.SkipEmptyStrings && *v.S == ""
+	case v.L != nil:
+		return opts.SkipEmptyCollections && len(v.L) == 0
+	case v.M != nil:
+		return opts.SkipEmptyCollections && len(v.M) == 0
+	case v.SS != nil:
+		return opts.SkipEmptyCollections && len(v.SS) == 0
+	case v.NS != nil:
+		return opts.SkipEmptyCollections && len(v.NS) == 0
+	case v.BS != nil:
+		return opts.SkipEmptyCollections && len(v.BS) == 0
+	}
+	return false
+}
+
+// rawValue passes an attribute value through expression.Value unchanged
+type rawValue struct {
+	av *dynamodb.AttributeValue
+}
+
+func (r rawValue) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	*av = *r.av
+	return nil
+}
@@ -0,0 +1,141 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type testAddress struct {
+	Street string        `dynamodbav:"street"`
+	Geo    testGeo       `dynamodbav:"geo"`
+	Tags   []string      `dynamodbav:"tags,omitempty"`
+	Notes  string        `dynamodbav:"notes,omitempty"`
+	Extra  *testGeo      `dynamodbav:"extra"`
+	Parts  []testAddress `dynamodbav:"parts,omitempty"`
+}
+
+type testGeo struct {
+	Lat  float64 `dynamodbav:"lat"`
+	Lng  float64 `dynamodbav:"lng"`
+	Name string  `dynamodbav:"name"`
+}
+
+type testCustomer struct {
+	ID        string        `dynamodbav:"id"`
+	Home      testAddress   `dynamodbav:"home"`
+	Addresses []testAddress `dynamodbav:"addresses"`
+	Ignored   string        `dynamodbav:"-"`
+}
+
+func TestMarshalItemNestedRoundTrip(t *testing.T) {
+	in := testCustomer{
+		ID:   "c1",
+		Home: testAddress{Street: "Main", Geo: testGeo{Lat: 1.5, Lng: -2, Name: "home"}, Tags: []string{"a"}},
+		Addresses: []testAddress{
+			{Street: "", Geo: testGeo{Lat: 3}, Notes: "second"},
+			{Street: "Side", Extra: &testGeo{Name: "x"}},
+		},
+		Ignored: "dropped",
+	}
+	item, err := MarshalItem(in, MarshalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := item["Ignored"]; ok {
+		t.Error("a field tagged - was marshaled")
+	}
+	home := item["home"].M
+	if home == nil || home["geo"].M["lat"] == nil || *home["geo"].M["lat"].N != "1.5" {
+		t.Fatalf("home wasn't marshaled as nested maps: %v", item["home"])
+	}
+	if _, ok := home["notes"]; ok {
+		t.Error("an empty omitempty field was marshaled")
+	}
+	if len(item["addresses"].L) != 2 || item["addresses"].L[0].M["street"].S == nil {
+		t.Fatalf("addresses weren't marshaled as a list of maps: %v", item["addresses"])
+	}
+	var out testCustomer
+	if err := dynamodbattribute.UnmarshalMap(item, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Ignored = ""
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestMarshalItemNestedPruning(t *testing.T) {
+	in := testCustomer{
+		ID:        "c1",
+		Addresses: []testAddress{{Street: "", Tags: []string{}}, {Street: "Side"}},
+	}
+	item, err := MarshalItem(in, MarshalOptions{SkipEmptyStrings: true, SkipEmptyCollections: true, SkipNulls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := item["addresses"].L[0].M
+	if len(item["addresses"].L) != 2 {
+		t.Fatalf("got %d addresses, want the list kept at 2", len(item["addresses"].L))
+	}
+	for _, name := range []string{"street", "extra"} {
+		if _, ok := first[name]; ok {
+			t.Errorf("empty %s was kept in a map inside a list", name)
+		}
+	}
+	if _, ok := item["home"].M["geo"].M["name"]; ok {
+		t.Error("empty name was kept two levels down")
+	}
+	var out testCustomer
+	if err := dynamodbattribute.UnmarshalMap(item, &out); err != nil {
+		t.Fatal(err)
+	}
+	// skipped attributes unmarshal to the zero value of their field
+	want := testCustomer{ID: "c1", Addresses: []testAddress{{}, {Street: "Side"}}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("got %+v, want %+v", out, want)
+	}
+}
+
+func TestMarshalItemNumericCollections(t *testing.T) {
+	in := struct {
+		Counts  map[string]int     `dynamodbav:"counts"`
+		Weights map[string]float64 `dynamodbav:"weights"`
+		Scores  []int              `dynamodbav:"scores"`
+		Set     []int              `dynamodbav:"set,numberset"`
+	}{
+		Counts:  map[string]int{"a": 1, "b": 2},
+		Weights: map[string]float64{"x": 0.25},
+		Scores:  []int{3, 1, 3},
+		Set:     []int{3, 1},
+	}
+	item, err := MarshalItem(in, MarshalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]map[string]string{"counts": {"a": "1", "b": "2"}, "weights": {"x": "0.25"}} {
+		v := item[name]
+		if v.M == nil || v.NS != nil {
+			t.Errorf("%s: got %v, want a Map of Numbers", name, v)
+			continue
+		}
+		for k, n := range want {
+			if v.M[k] == nil || v.M[k].N == nil || *v.M[k].N != n {
+				t.Errorf("%s.%s: got %v, want N %s", name, k, v.M[k], n)
+			}
+		}
+	}
+	scores := item["scores"]
+	if scores.NS != nil || len(scores.L) != 3 {
+		t.Fatalf("scores: got %v, want a List of 3 Numbers", scores)
+	}
+	for i, n := range []string{"3", "1", "3"} {
+		if scores.L[i].N == nil || *scores.L[i].N != n {
+			t.Errorf("scores[%d]: got %v, want N %s", i, scores.L[i], n)
+		}
+	}
+	if set := item["set"]; set.L != nil || len(set.NS) != 2 {
+		t.Errorf("set: got %v, want a Number Set", set)
+	}
+}
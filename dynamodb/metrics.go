@@ -0,0 +1,43 @@
+package dynamodb
+
+import "time"
+
+// Summary totals a helper call, see WithSummary. Retries counts the retries
+// of the helper, e.g. of unprocessed batch items, not those of the SDK.
+type Summary struct {
+	Operation string
+	// Items read or written
+	Items int
+	// Pages fetched by queries and scans
+	Pages   int
+	Retries int
+	// Capacity consumed, in capacity units
+	Capacity float64
+	Elapsed  time.Duration
+	// Err is the error the call returned
+	Err error
+}
+
+// Metrics receives per-operation latency and errors from every helper,
+// plug in Prometheus, statsd or similar by implementing it
+type Metrics interface {
+	ObserveLatency(op string, d time.Duration)
+	IncrError(op string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveLatency(string, time.Duration) {}
+
+func (noopMetrics) IncrError(string) {}
+
+var metrics Metrics = noopMetrics{}
+
+// SetMetrics installs m for all helpers, nil restores the no-op default.
+// It should be called before the helpers are used.
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = noopMetrics{}
+	}
+	metrics = m
+}
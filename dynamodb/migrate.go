@@ -0,0 +1,82 @@
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// RenameAttribute moves the attribute from to to on every record of table
+// that has it and returns how many records it migrated, see
+// ResumeRenameAttribute
+// batchSize: records scanned per page
+func RenameAttribute(ctx context.Context, client *dynamodb.DynamoDB, table, from, to string, batchSize int, opts ...Option) (int, error) {
+	return ResumeRenameAttribute(ctx, client, table, from, to, batchSize, nil, nil, opts...)
+}
+
+// ResumeRenameAttribute renames like RenameAttribute starting at the scan
+// cursor start, after every page it calls checkpoint with the cursor of the
+// next page, nil once done, so an interrupted migration can resume there.
+// Each record is updated with SET to = from REMOVE from while from exists,
+// throttled updates are retried with the Backoff of the call.
+// start: nil scans from the beginning
+// checkpoint: may be nil
+func ResumeRenameAttribute(ctx context.Context, client *dynamodb.DynamoDB, table, from, to string, batchSize int, start map[string]*dynamodb.AttributeValue, checkpoint func(next map[string]*dynamodb.AttributeValue) error, opts ...Option) (migrated int, err error) {
+	ctx, op := startOp(ctx, "RenameAttribute", table, opts)
+	defer op.end(&err)
+	names, err := op.keyAttributes(ctx, client, table, "")
+	if err != nil {
+		return 0, err
+	}
+	filter := WhereExists(from)
+	p := projection(names)
+	expr, err := exprParts{filter: &filter, projection: &p}.build()
+	if err != nil {
+		return 0, err
+	}
+	input := &dynamodb.ScanInput{
+		ExclusiveStartKey:         start,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     pageLimit(int64(batchSize), 0, 0),
+		ProjectionExpression:      expr.Projection(),
+		ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:                 aws.String(table),
+	}
+	update := expression.Set(expression.Name(to), expression.Name(from)).Remove(expression.Name(from))
+	for {
+		if err := op.nextPage(); err != nil {
+			return migrated, err
+		}
+		result, err := client.ScanWithContext(ctx, input)
+		if err != nil {
+			return migrated, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		for _, key := range result.Items {
+			err := Retry(ctx, func() error {
+				_, err := updateItem(ctx, client, table, key, update, WhereExists(from), dynamodb.ReturnValueNone, op)
+				return err
+			}, op.opts.Backoff)
+			if isConditionFailed(err) {
+				continue // renamed concurrently
+			}
+			if err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+		if checkpoint != nil {
+			if err := checkpoint(result.LastEvaluatedKey); err != nil {
+				return migrated, err
+			}
+		}
+		if result.LastEvaluatedKey == nil {
+			return migrated, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
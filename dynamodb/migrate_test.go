@@ -0,0 +1,138 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// testRename is a table keyed by id for RenameAttribute, it scans the
+// records in id order and applies SET #to = #from REMOVE #from updates
+type testRename struct {
+	t       *testing.T
+	records map[string]map[string]*dynamodb.AttributeValue
+	// starts are the ExclusiveStartKey ids of the scans, "" for none
+	starts  []string
+	updates map[string]int
+}
+
+var testRenameSet = regexp.MustCompile(`SET (#\w+) = (#\w+)`)
+
+func (s *testRename) handle(op string, body []byte) (int, interface{}) {
+	switch op {
+	case "DescribeTable":
+		return http.StatusOK, dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+			KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+		}}
+	case "Scan":
+		var input dynamodb.ScanInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			s.t.Error(err)
+		}
+		start := ""
+		if input.ExclusiveStartKey != nil {
+			start = aws.StringValue(input.ExclusiveStartKey["id"].S)
+		}
+		s.starts = append(s.starts, start)
+		ids := make([]string, 0, len(s.records))
+		for id := range s.records {
+			if id > start {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		var out dynamodb.ScanOutput
+		if n := int(aws.Int64Value(input.Limit)); n < len(ids) {
+			ids = ids[:n]
+			out.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"id": {S: aws.String(ids[n-1])}}
+		}
+		// the filter is applied after the limit, like DynamoDB does
+		for _, id := range ids {
+			if s.records[id]["old"] != nil {
+				out.Items = append(out.Items, map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}})
+			}
+		}
+		return http.StatusOK, out
+	case "UpdateItem":
+		var input dynamodb.UpdateItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			s.t.Error(err)
+		}
+		m := testRenameSet.FindStringSubmatch(aws.StringValue(input.UpdateExpression))
+		if m == nil {
+			s.t.Errorf("unexpected update %q", aws.StringValue(input.UpdateExpression))
+			return http.StatusBadRequest, fakeError("ValidationException")
+		}
+		to, from := aws.StringValue(input.ExpressionAttributeNames[m[1]]), aws.StringValue(input.ExpressionAttributeNames[m[2]])
+		id := aws.StringValue(input.Key["id"].S)
+		record := s.records[id]
+		if record[from] == nil {
+			return http.StatusBadRequest, fakeError(dynamodb.ErrCodeConditionalCheckFailedException)
+		}
+		s.updates[id]++
+		record[to] = record[from]
+		delete(record, from)
+		return http.StatusOK, dynamodb.UpdateItemOutput{}
+	}
+	return http.StatusBadRequest, fakeError("UnknownOperationException")
+}
+
+func TestResumeRenameAttribute(t *testing.T) {
+	store := &testRename{t: t, records: map[string]map[string]*dynamodb.AttributeValue{}, updates: map[string]int{}}
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprint(i)
+		store.records[id] = map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}, "old": {S: aws.String("v" + id)}}
+	}
+	// already renamed by an earlier run
+	store.records["2"] = map[string]*dynamodb.AttributeValue{"id": {S: aws.String("2")}, "new": {S: aws.String("v2")}}
+	client := newFakeClient(t, store.handle)
+	ctx := context.Background()
+
+	// the first run is interrupted after its first page
+	errStop := errors.New("stop")
+	var cursor map[string]*dynamodb.AttributeValue
+	migrated, err := ResumeRenameAttribute(ctx, client, "t", "old", "new", 2, nil, func(next map[string]*dynamodb.AttributeValue) error {
+		cursor = next
+		return errStop
+	})
+	if !errors.Is(err, errStop) || migrated != 2 {
+		t.Fatalf("first run: got %d migrated, err %v, want 2 and the checkpoint error", migrated, err)
+	}
+	if aws.StringValue(cursor["id"].S) != "1" {
+		t.Fatalf("got cursor %v, want id 1", cursor)
+	}
+
+	migrated, err = ResumeRenameAttribute(ctx, client, "t", "old", "new", 2, cursor, func(next map[string]*dynamodb.AttributeValue) error {
+		cursor = next
+		return nil
+	})
+	if err != nil || migrated != 2 {
+		t.Fatalf("resumed run: got %d migrated, err %v, want 2", migrated, err)
+	}
+	if cursor != nil {
+		t.Errorf("got cursor %v after the last page, want nil", cursor)
+	}
+	if want := []string{"", "1", "3"}; fmt.Sprint(store.starts) != fmt.Sprint(want) {
+		t.Errorf("got scans starting at %q, want %q", store.starts, want)
+	}
+	for id, record := range store.records {
+		if record["old"] != nil || aws.StringValue(record["new"].S) != "v"+id {
+			t.Errorf("record %s: got %v, want old renamed to new", id, record)
+		}
+		want := 1
+		if id == "2" {
+			want = 0
+		}
+		if store.updates[id] != want {
+			t.Errorf("record %s updated %d times, want %d", id, store.updates[id], want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package dynamodb
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Logger is satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Options tunes a single helper call, every helper accepts Option values
+// to set them
+type Options struct {
+	// ConsistentRead requests strongly consistent reads
+	ConsistentRead bool
+	// ReturnConsumedCapacity is NONE by default, and TOTAL when the call is
+	// traced or fills a Summary so they report the capacity
+	ReturnConsumedCapacity string
+	// PageSize sets the Limit of each request, 0 keeps the DynamoDB default
+	PageSize int64
+	// Backoff controls the retries of unprocessed items, DefaultBackoff by default
+	Backoff BackoffConfig
+	// Logger receives retry notices, nil disables logging
+	Logger Logger
+	// Metrics overrides the Metrics installed with SetMetrics
+	Metrics Metrics
+	// Cache serves queries from memory, see WithCache
+	Cache *QueryCache
+	// ConditionError makes helpers reporting a condition as false return
+	// ErrConditionFailed instead
+	ConditionError bool
+	// StrongFallback retries an eventually consistent read that found nothing
+	// with a strongly consistent one
+	StrongFallback bool
+	// UniqueResult makes single record lookups fail with ErrMultipleResults
+	// when more than one record matches
+	UniqueResult bool
+	// Index makes scans read a secondary index instead of the base table
+	Index string
+	// Concurrency bounds the requests fan-out helpers run at once, 8 by default
+	Concurrency int
+	// SizeCheck reads the item before an update to reject it early when the
+	// item would exceed MaxItemSize
+	SizeCheck bool
+	// Predicate drops the queried records it returns false for, client side
+	Predicate func(item map[string]*dynamodb.AttributeValue) bool
+	// Coercions converts attribute types before typed helpers unmarshal
+	Coercions Coercions
+	// SinglePage makes query and scan helpers send one request starting
+	// after the key it points to, and receives its LastEvaluatedKey
+	SinglePage *map[string]*dynamodb.AttributeValue
+	// PartialTimeout bounds a query, which then returns the records read so
+	// far with its error
+	PartialTimeout time.Duration
+	// Duplicates checks batch writes for repeated keys, unchecked by default
+	Duplicates DuplicateKeys
+	// Summary receives the totals of the call when it returns
+	Summary *Summary
+	// MaxPages caps the pages a query or scan fetches, 0 means no limit
+	MaxPages int
+
+	// defaultCapacity is set when ReturnConsumedCapacity wasn't chosen by
+	// an option, startOp then requests TOTAL only if the capacity is used
+	defaultCapacity bool
+}
+
+// DuplicateKeys selects what batch writes do with several writes of the
+// same key within one BatchWriteItem call, which DynamoDB rejects as a whole
+type DuplicateKeys int
+
+const (
+	// DuplicatesUnchecked sends the batch as is
+	DuplicatesUnchecked DuplicateKeys = iota
+	// DuplicatesError fails with ErrDuplicateKey naming the key
+	DuplicatesError
+	// DuplicatesKeepLast drops all but the last write of each key
+	DuplicatesKeepLast
+)
+
+// Option sets a field of Options
+type Option func(*Options)
+
+// WithConsistentRead requests strongly consistent reads
+func WithConsistentRead() Option {
+	return func(o *Options) {
+		o.ConsistentRead = true
+	}
+}
+
+// WithReturnConsumedCapacity sets the ReturnConsumedCapacity mode, e.g. dynamodb.ReturnConsumedCapacityIndexes
+func WithReturnConsumedCapacity(mode string) Option {
+	return func(o *Options) {
+		o.ReturnConsumedCapacity = mode
+	}
+}
+
+// WithPageSize sets the number of items evaluated per request
+func WithPageSize(n int64) Option {
+	return func(o *Options) {
+		o.PageSize = n
+	}
+}
+
+// WithBackoff sets the retry policy
+func WithBackoff(cfg BackoffConfig) Option {
+	return func(o *Options) {
+		o.Backoff = cfg
+	}
+}
+
+// WithLogger sets the logger
+func WithLogger(l Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithMetrics reports the call to m instead of the package Metrics
+func WithMetrics(m Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
+// WithConditionError makes helpers such as DeleteIf return ErrConditionFailed
+// when their condition doesn't hold, instead of false and a nil error
+func WithConditionError() Option {
+	return func(o *Options) {
+		o.ConditionError = true
+	}
+}
+
+// WithStrongFallback retries a read that found nothing once with a strongly
+// consistent read, e.g. right after the record was written. A miss then costs
+// twice the capacity.
+func WithStrongFallback() Option {
+	return func(o *Options) {
+		o.StrongFallback = true
+	}
+}
+
+// WithUniqueResult makes QueryOne return ErrMultipleResults when more than
+// one record matches
+func WithUniqueResult() Option {
+	return func(o *Options) {
+		o.UniqueResult = true
+	}
+}
+
+// WithIndex makes the scan helpers scan a secondary index, e.g. a sparse GSI
+// holding far fewer items than the table. Records only carry the attributes
+// projected into the index, for a KEYS_ONLY or INCLUDE projection the others
+// are missing, and typed helpers leave their fields at the zero value. Global
+// secondary indexes reject WithConsistentRead.
+func WithIndex(name string) Option {
+	return func(o *Options) {
+		o.Index = name
+	}
+}
+
+// WithConcurrency sets how many requests fan-out helpers such as UpdateMany
+// run at once
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+// WithSizeCheck makes update helpers read the item with a consistent read
+// first and return ErrItemTooLarge, without writing, when the update would
+// push it over MaxItemSize, e.g. for items growing with list_append. The
+// projected size is an estimate erring on the large side.
+func WithSizeCheck() Option {
+	return func(o *Options) {
+		o.SizeCheck = true
+	}
+}
+
+// WithPredicate filters the records of query helpers with fn after each page
+// is fetched, for conditions DynamoDB filters can't express such as regular
+// expressions. The dropped records are still read and charged, and query
+// results are not cached.
+func WithPredicate(fn func(item map[string]*dynamodb.AttributeValue) bool) Option {
+	return func(o *Options) {
+		o.Predicate = fn
+	}
+}
+
+// WithCoercions converts the types of attributes of legacy data before typed
+// helpers unmarshal it, see Coercions
+func WithCoercions(c Coercions) Option {
+	return func(o *Options) {
+		o.Coercions = c
+	}
+}
+
+// WithPartialResults bounds the pagination of QueryRecords, Query and the
+// helpers returning their records as is to timeout. When it runs out the
+// records of the pages read so far are returned along with an error wrapping
+// context.DeadlineExceeded, instead of discarding them, e.g. for best effort
+// dashboards. Check the error before trusting the records to be complete.
+func WithPartialResults(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.PartialTimeout = timeout
+	}
+}
+
+// WithDuplicateKeys makes batch writes check each BatchWriteItem call for
+// repeated keys before sending it. Locating the key of a put needs the key
+// schema of the table, which is described once per call.
+func WithDuplicateKeys(mode DuplicateKeys) Option {
+	return func(o *Options) {
+		o.Duplicates = mode
+	}
+}
+
+// WithSummary fills s with the totals of the call when it returns, one value
+// to log after a bulk query, scan or import instead of several
+func WithSummary(s *Summary) Option {
+	return func(o *Options) {
+		o.Summary = s
+	}
+}
+
+// WithMaxPages makes query and scan helpers return ErrPageLimitExceeded
+// instead of fetching more than n pages, a safety valve against key
+// conditions or indexes that match far more than expected
+func WithMaxPages(n int) Option {
+	return func(o *Options) {
+		o.MaxPages = n
+	}
+}
+
+// WithSinglePage makes query and scan helpers send one request, starting
+// after *next unless it is nil, and store its LastEvaluatedKey in next, nil
+// once there are no more records. Calling a helper again with the same next
+// fetches the following page. Query results are not cached.
+func WithSinglePage(next *map[string]*dynamodb.AttributeValue) Option {
+	return func(o *Options) {
+		o.SinglePage = next
+	}
+}
+
+func newOptions(opts []Option) Options {
+	o := Options{
+		Backoff:     DefaultBackoff,
+		Concurrency: 8,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ReturnConsumedCapacity == "" {
+		o.ReturnConsumedCapacity = dynamodb.ReturnConsumedCapacityNone
+		o.defaultCapacity = true
+	}
+	return o
+}
+
+// consistentRead returns the ConsistentRead field of a request, nil keeps the default
+func consistentRead(o Options) *bool {
+	if o.ConsistentRead {
+		return aws.Bool(true)
+	}
+	return nil
+}
+
+func (o Options) logf(format string, v ...interface{}) {
+	if o.Logger != nil {
+		o.Logger.Printf(format, v...)
+	}
+}
+
+func (o Options) metrics() Metrics {
+	if o.Metrics != nil {
+		return o.Metrics
+	}
+	return metrics
+}
@@ -0,0 +1,28 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestReturnConsumedCapacityDefault(t *testing.T) {
+	var summary Summary
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"default", nil, dynamodb.ReturnConsumedCapacityNone},
+		{"summary", []Option{WithSummary(&summary)}, dynamodb.ReturnConsumedCapacityTotal},
+		{"explicit", []Option{WithReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityIndexes)}, dynamodb.ReturnConsumedCapacityIndexes},
+		{"explicit none", []Option{WithSummary(&summary), WithReturnConsumedCapacity(dynamodb.ReturnConsumedCapacityNone)}, dynamodb.ReturnConsumedCapacityNone},
+	}
+	for _, tt := range tests {
+		_, op := startOp(context.Background(), "Test", "t", tt.opts)
+		if got := op.opts.ReturnConsumedCapacity; got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
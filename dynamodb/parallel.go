@@ -0,0 +1,169 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// ParallelScanIterator yields the records of a table as T while scanning its
+// segments concurrently. Every segment holds at most one page in memory.
+type ParallelScanIterator[T any] struct {
+	items chan T
+	// op counts the pages of every segment under opMu, it ends once all
+	// segments exited
+	op     *operation
+	opMu   sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	value  T
+	err    error
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewParallelScanIterator func starts scanning the segments of table and
+// returns an iterator over the records matching filter. The pages of all
+// segments count as one operation, MaxPages bounds their total. WithSinglePage
+// is rejected since one cursor can't resume several segments.
+// segments: number of concurrent scan segments
+func NewParallelScanIterator[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, segments int, opts ...Option) (_ *ParallelScanIterator[T], err error) {
+	ctx, op := startOp(ctx, "ParallelScanIterator", table, opts)
+	if segments < 1 {
+		err = fmt.Errorf("dynamodb: segments must be at least 1, got %d", segments)
+		op.end(&err)
+		return nil, err
+	}
+	if op.opts.SinglePage != nil {
+		err = fmt.Errorf("dynamodb: a parallel scan can't be read one page at a time")
+		op.end(&err)
+		return nil, err
+	}
+	base, err := scanInput(table, filter, op.opts)
+	if err != nil {
+		op.end(&err)
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	it := &ParallelScanIterator[T]{items: make(chan T, segments), op: op, cancel: cancel}
+	for i := 0; i < segments; i++ {
+		input := *base
+		input.Segment = aws.Int64(int64(i))
+		input.TotalSegments = aws.Int64(int64(segments))
+		it.wg.Add(1)
+		go it.scanSegment(ctx, client, &input)
+	}
+	go func() {
+		it.wg.Wait()
+		it.mu.Lock()
+		err := it.err
+		it.op.end(&err)
+		it.err = err
+		it.mu.Unlock()
+		close(it.items)
+	}()
+	return it, nil
+}
+
+func (it *ParallelScanIterator[T]) scanSegment(ctx context.Context, client *dynamodb.DynamoDB, input *dynamodb.ScanInput) {
+	defer it.wg.Done()
+	// keys describes the key schema for the ItemError of the segment
+	var keys operation
+	for {
+		result, err := it.scan(ctx, client, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			it.fail(err)
+			return
+		}
+		for _, item := range result.Items {
+			var value T
+			if err := unmarshalItem(item, &value, it.op.opts.Coercions); err != nil {
+				it.fail(&ItemError{Key: keys.itemKey(ctx, client, aws.StringValue(input.TableName), item), Err: err})
+				return
+			}
+			select {
+			case it.items <- value:
+			case <-ctx.Done():
+				it.fail(ctx.Err())
+				return
+			}
+		}
+		if result.LastEvaluatedKey == nil {
+			return
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// scan fetches the next page of a segment and counts it in op
+func (it *ParallelScanIterator[T]) scan(ctx context.Context, client *dynamodb.DynamoDB, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	it.opMu.Lock()
+	err := it.op.nextPage()
+	it.opMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.ScanWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	it.opMu.Lock()
+	it.op.addItems(len(result.Items))
+	it.op.addCapacity(result.ConsumedCapacity)
+	it.opMu.Unlock()
+	return result, nil
+}
+
+// fail records the first error and stops the other segments
+func (it *ParallelScanIterator[T]) fail(err error) {
+	it.once.Do(func() {
+		it.mu.Lock()
+		if !it.closed {
+			it.err = err
+		}
+		it.mu.Unlock()
+		it.cancel()
+	})
+}
+
+// Next advances to the next record. It returns false once every segment is
+// finished, an error occurred or the context was cancelled, and only after
+// all scanning goroutines have exited.
+func (it *ParallelScanIterator[T]) Next() bool {
+	value, ok := <-it.items
+	if !ok {
+		return false
+	}
+	it.value = value
+	return true
+}
+
+// Value returns the current record
+func (it *ParallelScanIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the first error that stopped the scan, nil after Close
+func (it *ParallelScanIterator[T]) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.err
+}
+
+// Close stops the scan early and waits for all segments to exit
+func (it *ParallelScanIterator[T]) Close() {
+	it.mu.Lock()
+	it.closed = true
+	it.mu.Unlock()
+	it.cancel()
+	for range it.items {
+	}
+}
@@ -0,0 +1,82 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// fakeSegments answers the Scan requests of a parallel scan with two pages
+// of one item per segment
+func fakeSegments(t *testing.T) fakeHandler {
+	return func(op string, body []byte) (int, interface{}) {
+		var input dynamodb.ScanInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Error(err)
+		}
+		page := 0
+		if k := input.ExclusiveStartKey["page"]; k != nil {
+			page, _ = strconv.Atoi(aws.StringValue(k.N))
+		}
+		id := fmt.Sprint(aws.Int64Value(input.Segment), "/", page)
+		out := dynamodb.ScanOutput{
+			Items:            []map[string]*dynamodb.AttributeValue{{"id": {S: aws.String(id)}}},
+			ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(0.5)},
+		}
+		if page == 0 {
+			out.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"page": {N: aws.String("1")}}
+		}
+		return http.StatusOK, out
+	}
+}
+
+func TestParallelScanIteratorSummary(t *testing.T) {
+	client := newFakeClient(t, fakeSegments(t))
+	var summary Summary
+	it, err := NewParallelScanIterator[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, 4, WithSummary(&summary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[interface{}]bool{}
+	for it.Next() {
+		seen[it.Value()["id"]] = true
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 8 {
+		t.Errorf("got %d distinct records, want 8", len(seen))
+	}
+	if summary.Operation != "ParallelScanIterator" || summary.Items != 8 || summary.Pages != 8 || summary.Capacity != 4 {
+		t.Errorf("got %+v, want ParallelScanIterator with 8 items, 8 pages and 4 capacity", summary)
+	}
+}
+
+func TestParallelScanIteratorMaxPages(t *testing.T) {
+	client := newFakeClient(t, fakeSegments(t))
+	it, err := NewParallelScanIterator[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, 4, WithMaxPages(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it.Next() {
+	}
+	if !errors.Is(it.Err(), ErrPageLimitExceeded) {
+		t.Errorf("got %v, want ErrPageLimitExceeded", it.Err())
+	}
+}
+
+func TestParallelScanIteratorRejectsSinglePage(t *testing.T) {
+	client := newFakeClient(t, fakeSegments(t))
+	var next map[string]*dynamodb.AttributeValue
+	if _, err := NewParallelScanIterator[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, 2, WithSinglePage(&next)); err == nil {
+		t.Error("got no error for WithSinglePage")
+	}
+}
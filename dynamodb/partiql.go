@@ -0,0 +1,144 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ExecuteStatement runs a PartiQL statement and returns every resulting row,
+// following NextToken through all pages
+// statement: PartiQL statement, with ? placeholders for params
+func ExecuteStatement(ctx context.Context, client *dynamodb.DynamoDB, statement string, params []*dynamodb.AttributeValue, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "ExecuteStatement", "", opts)
+	defer op.end(&err)
+	return executeStatement(ctx, client, statement, params, op)
+}
+
+// ExecuteStatementTyped runs a PartiQL statement like ExecuteStatement and
+// unmarshals the rows into T, a row that fails is reported as *ItemError
+// holding the whole row
+func ExecuteStatementTyped[T any](ctx context.Context, client *dynamodb.DynamoDB, statement string, params []*dynamodb.AttributeValue, opts ...Option) (_ []T, err error) {
+	ctx, op := startOp(ctx, "ExecuteStatementTyped", "", opts)
+	defer op.end(&err)
+	items, err := executeStatement(ctx, client, statement, params, op)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]T, 0, len(items))
+	for _, item := range items {
+		var value T
+		if err := unmarshalItem(item, &value, op.opts.Coercions); err != nil {
+			return nil, &ItemError{Key: item, Err: err}
+		}
+		output = append(output, value)
+	}
+	return output, nil
+}
+
+func executeStatement(ctx context.Context, client *dynamodb.DynamoDB, statement string, params []*dynamodb.AttributeValue, op *operation) ([]map[string]*dynamodb.AttributeValue, error) {
+	input := &dynamodb.ExecuteStatementInput{
+		ConsistentRead:         consistentRead(op.opts),
+		Limit:                  pageLimit(op.opts.PageSize, 0, 0),
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		Statement:              aws.String(statement),
+	}
+	if len(params) > 0 {
+		input.Parameters = params
+	}
+	var output []map[string]*dynamodb.AttributeValue
+	for {
+		result, err := client.ExecuteStatementWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		output = append(output, result.Items...)
+		if result.NextToken == nil {
+			break
+		}
+		input.NextToken = result.NextToken
+	}
+	op.addItems(len(output))
+	return output, nil
+}
+
+// Statement is one PartiQL statement of a batch
+type Statement struct {
+	Statement      string
+	Params         []*dynamodb.AttributeValue
+	ConsistentRead bool
+}
+
+// StatementResult is the outcome of one statement of a batch
+// Item: the item read by a SELECT statement
+// Err: a *StatementError when the statement failed
+type StatementResult struct {
+	Item map[string]*dynamodb.AttributeValue
+	Err  error
+}
+
+// StatementError reports a failed statement of a batch, it matches
+// ErrConditionFailed and ErrTableNotFound with errors.Is
+type StatementError struct {
+	Code    string
+	Message string
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("dynamodb: statement failed: %s: %s", e.Code, e.Message)
+}
+
+func (e *StatementError) Is(target error) bool {
+	switch target {
+	case ErrConditionFailed:
+		return e.Code == dynamodb.BatchStatementErrorCodeEnumConditionalCheckFailed
+	case ErrTableNotFound:
+		return e.Code == dynamodb.BatchStatementErrorCodeEnumResourceNotFound
+	}
+	return false
+}
+
+// MaxBatchStatements is the most statements BatchExecuteStatement accepts
+const MaxBatchStatements = 25
+
+// BatchExecuteStatement runs up to 25 PartiQL statements in one request, the
+// results are aligned to statements and carry the per-statement errors.
+// The returned error is only set when the request as a whole failed.
+func BatchExecuteStatement(ctx context.Context, client *dynamodb.DynamoDB, statements []Statement, opts ...Option) (_ []StatementResult, err error) {
+	ctx, op := startOp(ctx, "BatchExecuteStatement", "", opts)
+	defer op.end(&err)
+	if len(statements) > MaxBatchStatements {
+		return nil, fmt.Errorf("dynamodb: %d statements exceed the batch limit of %d", len(statements), MaxBatchStatements)
+	}
+	input := &dynamodb.BatchExecuteStatementInput{
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+	}
+	for _, s := range statements {
+		request := &dynamodb.BatchStatementRequest{Statement: aws.String(s.Statement)}
+		if len(s.Params) > 0 {
+			request.Parameters = s.Params
+		}
+		if s.ConsistentRead || op.opts.ConsistentRead {
+			request.ConsistentRead = aws.Bool(true)
+		}
+		input.Statements = append(input.Statements, request)
+	}
+	result, err := client.BatchExecuteStatementWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	op.addCapacity(result.ConsumedCapacity...)
+	output := make([]StatementResult, len(result.Responses))
+	for i, r := range result.Responses {
+		output[i].Item = r.Item
+		if r.Error != nil {
+			output[i].Err = &StatementError{Code: aws.StringValue(r.Error.Code), Message: aws.StringValue(r.Error.Message)}
+		} else {
+			op.addItems(1)
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,26 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestExecuteStatementTypedItemError(t *testing.T) {
+	bad := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("b")}, "n": {S: aws.String("not a number")}}
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		return http.StatusOK, dynamodb.ExecuteStatementOutput{Items: []map[string]*dynamodb.AttributeValue{bad}}
+	})
+	_, err := ExecuteStatementTyped[struct{ N int }](context.Background(), client, `SELECT * FROM "t"`, nil)
+	var ierr *ItemError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("got %v, want *ItemError", err)
+	}
+	if KeyString(ierr.Key) != KeyString(bad) {
+		t.Errorf("got key %s, want the failing row %s", KeyString(ierr.Key), KeyString(bad))
+	}
+}
@@ -0,0 +1,86 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// UpsertFields sets the attributes named in mask to their values in v, a
+// struct, and leaves the other attributes of the record alone, creating the
+// record if it doesn't exist. Masked fields are written even when they hold
+// their zero value and are tagged omitempty, so a PATCH can set a counter to
+// 0 or a flag to false. A masked nil pointer, map or slice removes the
+// attribute. Key attributes must not be in mask.
+// mask: attribute names as given by the dynamodbav tags of v
+func UpsertFields(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, v interface{}, mask []string, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "UpsertFields", table, opts)
+	defer op.end(&err)
+	if len(mask) == 0 {
+		return fmt.Errorf("dynamodb: empty field mask")
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("dynamodb: UpsertFields needs a struct, got %T", v)
+	}
+	encoder := dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+		e.NullEmptyString = false
+		e.EnableEmptyCollections = true
+	})
+	var update expression.UpdateBuilder
+	for _, name := range mask {
+		field, ok := fieldByAttr(rv, name)
+		if !ok {
+			return fmt.Errorf("dynamodb: %T has no field for attribute %q", v, name)
+		}
+		av, err := encoder.Encode(field.Interface())
+		if err != nil {
+			return err
+		}
+		if av == nil || av.NULL != nil {
+			update = update.Remove(expression.Name(name))
+			continue
+		}
+		update = update.Set(expression.Name(name), expression.Value(rawValue{av}))
+	}
+	_, err = updateItem(ctx, client, table, key, update, expression.ConditionBuilder{}, dynamodb.ReturnValueNone, op)
+	return err
+}
+
+// fieldByAttr finds the field of struct rv marshaled as the attribute name,
+// looking into embedded structs like dynamodbattribute does
+func fieldByAttr(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("dynamodbav")
+		if tag == "-" {
+			continue
+		}
+		attr := strings.Split(tag, ",")[0]
+		if f.Anonymous && attr == "" {
+			embedded := reflect.Indirect(rv.Field(i))
+			if embedded.Kind() == reflect.Struct {
+				if field, ok := fieldByAttr(embedded, name); ok {
+					return field, true
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if attr == "" {
+			attr = f.Name
+		}
+		if attr == name {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,132 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ToStruct converts item into a protobuf Struct so gRPC services can return
+// it without a message definition. S becomes a string, N a number, BOOL a
+// bool, NULL null, L a list and M a nested Struct. Numbers are float64 and
+// are silently rounded beyond 15 significant digits. Sets become lists and
+// binary values base64 strings, so those types come back from FromStruct as
+// L and S. An attribute value without a type is rejected.
+func ToStruct(item map[string]*dynamodb.AttributeValue) (*structpb.Struct, error) {
+	fields := make(map[string]*structpb.Value, len(item))
+	for name, v := range item {
+		value, err := toProtoValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("dynamodb: converting %q: %w", name, err)
+		}
+		fields[name] = value
+	}
+	return &structpb.Struct{Fields: fields}, nil
+}
+
+// FromStruct converts a protobuf Struct into an item, the reverse of ToStruct.
+// Numbers are written without exponent, NaN and infinities are rejected.
+func FromStruct(s *structpb.Struct) (map[string]*dynamodb.AttributeValue, error) {
+	item := make(map[string]*dynamodb.AttributeValue, len(s.GetFields()))
+	for name, v := range s.GetFields() {
+		av, err := fromProtoValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("dynamodb: converting %q: %w", name, err)
+		}
+		item[name] = av
+	}
+	return item, nil
+}
+
+func toProtoValue(v *dynamodb.AttributeValue) (*structpb.Value, error) {
+	switch {
+	case v == nil || v.NULL != nil:
+		return structpb.NewNullValue(), nil
+	case v.S != nil:
+		return structpb.NewStringValue(*v.S), nil
+	case v.N != nil:
+		n, err := strconv.ParseFloat(*v.N, 64)
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewNumberValue(n), nil
+	case v.BOOL != nil:
+		return structpb.NewBoolValue(*v.BOOL), nil
+	case v.B != nil:
+		return structpb.NewStringValue(base64.StdEncoding.EncodeToString(v.B)), nil
+	case v.M != nil:
+		s, err := ToStruct(v.M)
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewStructValue(s), nil
+	}
+	var values []*structpb.Value
+	switch {
+	case v.L != nil:
+		for _, e := range v.L {
+			value, err := toProtoValue(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	case v.SS != nil:
+		for _, e := range v.SS {
+			values = append(values, structpb.NewStringValue(aws.StringValue(e)))
+		}
+	case v.NS != nil:
+		for _, e := range v.NS {
+			value, err := toProtoValue(&dynamodb.AttributeValue{N: e})
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	case v.BS != nil:
+		for _, e := range v.BS {
+			values = append(values, structpb.NewStringValue(base64.StdEncoding.EncodeToString(e)))
+		}
+	default:
+		return nil, fmt.Errorf("empty attribute value")
+	}
+	return structpb.NewListValue(&structpb.ListValue{Values: values}), nil
+}
+
+func fromProtoValue(v *structpb.Value) (*dynamodb.AttributeValue, error) {
+	switch kind := v.GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+		return &dynamodb.AttributeValue{NULL: aws.Bool(true)}, nil
+	case *structpb.Value_StringValue:
+		return &dynamodb.AttributeValue{S: aws.String(kind.StringValue)}, nil
+	case *structpb.Value_NumberValue:
+		if math.IsInf(kind.NumberValue, 0) || math.IsNaN(kind.NumberValue) {
+			return nil, fmt.Errorf("%v is not a DynamoDB number", kind.NumberValue)
+		}
+		return &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(kind.NumberValue, 'f', -1, 64))}, nil
+	case *structpb.Value_BoolValue:
+		return &dynamodb.AttributeValue{BOOL: aws.Bool(kind.BoolValue)}, nil
+	case *structpb.Value_StructValue:
+		m, err := FromStruct(kind.StructValue)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamodb.AttributeValue{M: m}, nil
+	case *structpb.Value_ListValue:
+		l := make([]*dynamodb.AttributeValue, 0, len(kind.ListValue.GetValues()))
+		for _, e := range kind.ListValue.GetValues() {
+			av, err := fromProtoValue(e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, av)
+		}
+		return &dynamodb.AttributeValue{L: l}, nil
+	}
+	return nil, fmt.Errorf("unsupported protobuf value %T", v.GetKind())
+}
@@ -0,0 +1,77 @@
+package dynamodb
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoStructConversion(t *testing.T) {
+	list, _ := structpb.NewList([]interface{}{"a", 1.0})
+	nested, _ := structpb.NewStruct(map[string]interface{}{"n": 2.0})
+	tests := []struct {
+		name  string
+		av    *dynamodb.AttributeValue
+		value *structpb.Value
+		// back is the attribute value FromStruct returns, av when nil
+		back *dynamodb.AttributeValue
+	}{
+		{"S", &dynamodb.AttributeValue{S: aws.String("x")}, structpb.NewStringValue("x"), nil},
+		{"N", &dynamodb.AttributeValue{N: aws.String("1.5")}, structpb.NewNumberValue(1.5), nil},
+		{"N negative", &dynamodb.AttributeValue{N: aws.String("-42")}, structpb.NewNumberValue(-42), nil},
+		{"BOOL", &dynamodb.AttributeValue{BOOL: aws.Bool(true)}, structpb.NewBoolValue(true), nil},
+		{"NULL", &dynamodb.AttributeValue{NULL: aws.Bool(true)}, structpb.NewNullValue(), nil},
+		{"L", &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{S: aws.String("a")}, {N: aws.String("1")}}}, structpb.NewListValue(list), nil},
+		{"M", &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{"n": {N: aws.String("2")}}}, structpb.NewStructValue(nested), nil},
+		// lossy: the types below don't survive the round trip
+		{"N exponent", &dynamodb.AttributeValue{N: aws.String("1e3")}, structpb.NewNumberValue(1000), &dynamodb.AttributeValue{N: aws.String("1000")}},
+		{"N beyond float64", &dynamodb.AttributeValue{N: aws.String("12345678901234567890")}, structpb.NewNumberValue(12345678901234567890), &dynamodb.AttributeValue{N: aws.String("12345678901234567000")}},
+		{"B", &dynamodb.AttributeValue{B: []byte("hi")}, structpb.NewStringValue("aGk="), &dynamodb.AttributeValue{S: aws.String("aGk=")}},
+		{"SS", &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"a"})}, structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("a")}}), &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{S: aws.String("a")}}}},
+		{"NS", &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1"})}, structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{structpb.NewNumberValue(1)}}), &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{N: aws.String("1")}}}},
+		{"BS", &dynamodb.AttributeValue{BS: [][]byte{[]byte("hi")}}, structpb.NewListValue(&structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("aGk=")}}), &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{S: aws.String("aGk=")}}}},
+	}
+	for _, tt := range tests {
+		s, err := ToStruct(map[string]*dynamodb.AttributeValue{"v": tt.av})
+		if err != nil {
+			t.Errorf("%s: ToStruct: %v", tt.name, err)
+			continue
+		}
+		if !proto.Equal(s.Fields["v"], tt.value) {
+			t.Errorf("%s: ToStruct: got %v, want %v", tt.name, s.Fields["v"], tt.value)
+		}
+		item, err := FromStruct(s)
+		if err != nil {
+			t.Errorf("%s: FromStruct: %v", tt.name, err)
+			continue
+		}
+		back := tt.back
+		if back == nil {
+			back = tt.av
+		}
+		// AttributeEqual compares numbers by value, the digits are checked too
+		if !AttributeEqual(item["v"], back) || aws.StringValue(item["v"].N) != aws.StringValue(back.N) {
+			t.Errorf("%s: FromStruct: got %v, want %v", tt.name, item["v"], back)
+		}
+	}
+}
+
+func TestProtoStructRejects(t *testing.T) {
+	if _, err := ToStruct(map[string]*dynamodb.AttributeValue{"v": {}}); err == nil || !strings.Contains(err.Error(), `"v"`) {
+		t.Errorf("ToStruct of an untyped value: got %v, want an error naming v", err)
+	}
+	if _, err := ToStruct(map[string]*dynamodb.AttributeValue{"v": {N: aws.String("abc")}}); err == nil {
+		t.Error("ToStruct of an invalid number: got no error")
+	}
+	for _, n := range []float64{math.NaN(), math.Inf(1)} {
+		s := &structpb.Struct{Fields: map[string]*structpb.Value{"v": structpb.NewNumberValue(n)}}
+		if _, err := FromStruct(s); err == nil {
+			t.Errorf("FromStruct of %v: got no error", n)
+		}
+	}
+}
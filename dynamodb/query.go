@@ -0,0 +1,359 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// QueryOptions tunes a Query, the zero value queries the base table with
+// eventually consistent reads in ascending order
+type QueryOptions struct {
+	// ConsistentRead requests strongly consistent reads
+	ConsistentRead bool
+	// Limit caps the number of items evaluated across all pages, 0 means no cap
+	Limit int64
+	// ScanIndexForward sets the sort order, nil keeps the default ascending order
+	ScanIndexForward *bool
+	// ProjectionAttrs restricts the attributes returned
+	ProjectionAttrs []string
+	// IndexName queries a secondary index instead of the base table
+	IndexName string
+	// StartKey resumes a previous query from its LastEvaluatedKey
+	StartKey map[string]*dynamodb.AttributeValue
+}
+
+// Query returns the records matching keyCond and filter, paginating through
+// all results within the bounds of q
+// filter: an unset expression.ConditionBuilder queries without a filter
+func Query(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "Query", table, opts)
+	defer op.end(&err)
+	return query(ctx, client, table, keyCond, filter, q, op)
+}
+
+// QueryTyped returns the records matching keyCond and filter like Query and
+// unmarshals them into T. With q.ProjectionAttrs only those attributes are
+// fetched, the fields of T they don't cover are left at their zero value.
+func QueryTyped[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) (_ []T, err error) {
+	ctx, op := startOp(ctx, "QueryTyped", table, opts)
+	defer op.end(&err)
+	items, err := query(ctx, client, table, keyCond, filter, q, op)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]T, 0, len(items))
+	for _, item := range items {
+		var value T
+		if err := unmarshalItem(item, &value, op.opts.Coercions); err != nil {
+			return nil, &ItemError{Key: op.itemKey(ctx, client, table, item), Err: err}
+		}
+		output = append(output, value)
+	}
+	return output, nil
+}
+
+// LatestN returns the n records with the highest sort keys in the partition
+// pk = pkVal, newest first when the sort key is a timestamp, e.g. the latest
+// events of an activity feed. It reads no more than n records.
+// pk: partition key name, pkVal is sent as a string
+func LatestN(ctx context.Context, client *dynamodb.DynamoDB, table, pk, pkVal string, n int64, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "LatestN", table, opts)
+	defer op.end(&err)
+	if n <= 0 {
+		return nil, nil
+	}
+	keyCond := expression.Key(pk).Equal(expression.Value(pkVal))
+	return query(ctx, client, table, keyCond, expression.ConditionBuilder{}, QueryOptions{Limit: n, ScanIndexForward: aws.Bool(false)}, op)
+}
+
+// QueryIndex returns the records of a secondary index matching keyCond and filter,
+// any key condition works, not only equality on the partition key
+// index: DynamoDB index name
+func QueryIndex(ctx context.Context, client *dynamodb.DynamoDB, table, index string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryIndex", table, opts)
+	defer op.end(&err)
+	return query(ctx, client, table, keyCond, filter, QueryOptions{IndexName: index}, op)
+}
+
+// QueryOne returns the first record matching keyCond and filter, nil if there
+// is none. With WithUniqueResult a second match returns ErrMultipleResults,
+// e.g. for lookups on a GSI expected to be unique.
+func QueryOne(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryOne", table, opts)
+	defer op.end(&err)
+	input, err := queryInput(table, keyCond, filter, q, op.opts)
+	if err != nil {
+		return nil, err
+	}
+	want := 1
+	if op.opts.UniqueResult {
+		want = 2
+	}
+	var found []map[string]*dynamodb.AttributeValue
+	for len(found) < want {
+		if err := op.nextPage(); err != nil {
+			return nil, err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		found = append(found, keep(result.Items, op.opts.Predicate)...)
+		if op.lastPage(result.LastEvaluatedKey) {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	if op.opts.UniqueResult && len(found) > 1 {
+		return nil, ErrMultipleResults
+	}
+	op.addItems(1)
+	op.touch(found[0])
+	return found[0], nil
+}
+
+// QueryUpTo returns up to n records matching keyCond and filter, fetching
+// pages until it has n matches or the results run out. Limit and PageSize
+// of DynamoDB count items before filtering, so a selective filter needs extra
+// requests and is charged for every item it evaluates. Pass next as
+// q.StartKey to resume, it is nil when there are no more records.
+// q: q.Limit is ignored
+func QueryUpTo(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, n int, q QueryOptions, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, next map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryUpTo", table, opts)
+	defer op.end(&err)
+	q.Limit = 0
+	input, err := queryInput(table, keyCond, filter, q, op.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var output []map[string]*dynamodb.AttributeValue
+	for len(output) < n {
+		// never evaluate more than the matches still missing, so next
+		// points right after the last returned record
+		input.Limit = pageLimit(op.opts.PageSize, int64(n-len(output)), 0)
+		if err := op.nextPage(); err != nil {
+			return nil, nil, err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		output = append(output, keep(result.Items, op.opts.Predicate)...)
+		next = result.LastEvaluatedKey
+		if op.lastPage(next) {
+			break
+		}
+		input.ExclusiveStartKey = next
+	}
+	op.addItems(len(output))
+	return output, next, nil
+}
+
+// QueryPages calls fn with each page of records matching keyCond and filter
+// without accumulating them, an error returned by fn stops the query and is
+// returned as is
+func QueryPages(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, fn func(page []map[string]*dynamodb.AttributeValue) error, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "QueryPages", table, opts)
+	defer op.end(&err)
+	input, err := queryInput(table, keyCond, filter, QueryOptions{}, op.opts)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := op.nextPage(); err != nil {
+			return err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		page := keep(result.Items, op.opts.Predicate)
+		op.addItems(len(page))
+		if err := fn(page); err != nil {
+			return err
+		}
+		if op.lastPage(result.LastEvaluatedKey) {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// SparseIndexInput builds the QueryInput of a sparse GSI, whose key
+// attributes only some records have. DynamoDB only indexes the records
+// holding every key attribute of the index, so the result never contains the
+// others and no filter is needed to exclude them. Attributes projected into
+// the index but not set on every record are best filtered with MissingOr.
+// Run it with the client's QueryPages, QueryIndex takes the same arguments
+// and returns every record.
+// index: DynamoDB index name
+func SparseIndexInput(table, index string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, opts ...Option) (*dynamodb.QueryInput, error) {
+	return queryInput(table, keyCond, filter, QueryOptions{IndexName: index}, newOptions(opts))
+}
+
+func query(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, op *operation) ([]map[string]*dynamodb.AttributeValue, error) {
+	input, err := queryInput(table, keyCond, filter, q, op.opts)
+	if err != nil {
+		return nil, err
+	}
+	cache := op.opts.Cache
+	if op.opts.Predicate != nil || op.opts.SinglePage != nil {
+		cache = nil
+	}
+	if cache != nil {
+		if items, ok := cache.get(table, input); ok {
+			op.addItems(len(items))
+			return items, nil
+		}
+	}
+	if op.opts.PartialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, op.opts.PartialTimeout)
+		defer cancel()
+	}
+	request := *input
+	var output []map[string]*dynamodb.AttributeValue
+	var scanned int64
+	for {
+		input.Limit = pageLimit(op.opts.PageSize, q.Limit, scanned)
+		if err := op.nextPage(); err != nil {
+			return nil, err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			if op.opts.PartialTimeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				op.addItems(len(output))
+				return output, fmt.Errorf("dynamodb: partial results, %d records after %v: %w", len(output), op.opts.PartialTimeout, context.DeadlineExceeded)
+			}
+			return nil, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		output = append(output, keep(result.Items, op.opts.Predicate)...)
+		scanned += aws.Int64Value(result.ScannedCount)
+		if op.lastPage(result.LastEvaluatedKey) || (q.Limit > 0 && scanned >= q.Limit) {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	op.addItems(len(output))
+	if len(output) > 0 {
+		op.touch(output[0])
+	}
+	if cache != nil {
+		cache.put(table, &request, output)
+	}
+	return output, nil
+}
+
+// StartKeyFrom builds the ExclusiveStartKey resuming a query after last,
+// the last record of the previous page as a struct or an item. It takes the
+// key attributes of the table, and of the index if any, from DescribeTable,
+// since the start key of an index query needs both.
+// index: DynamoDB index name, empty for the base table
+func StartKeyFrom(ctx context.Context, client *dynamodb.DynamoDB, table, index string, last interface{}, opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "StartKeyFrom", table, opts)
+	defer op.end(&err)
+	item, ok := last.(map[string]*dynamodb.AttributeValue)
+	if !ok {
+		if item, err = dynamodbattribute.MarshalMap(last); err != nil {
+			return nil, err
+		}
+	}
+	names, err := op.keyAttributes(ctx, client, table, index)
+	if err != nil {
+		return nil, err
+	}
+	key := make(map[string]*dynamodb.AttributeValue, len(names))
+	for _, name := range names {
+		v, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("dynamodb: record has no %q attribute", name)
+		}
+		key[name] = v
+	}
+	return key, nil
+}
+
+// keep returns the items predicate accepts, all of them when it is nil
+func keep(items []map[string]*dynamodb.AttributeValue, predicate func(map[string]*dynamodb.AttributeValue) bool) []map[string]*dynamodb.AttributeValue {
+	if predicate == nil {
+		return items
+	}
+	var output []map[string]*dynamodb.AttributeValue
+	for _, item := range items {
+		if predicate(item) {
+			output = append(output, item)
+		}
+	}
+	return output
+}
+
+// pageLimit returns the Limit of the next request given the page size, the
+// overall limit and the number of items evaluated so far, nil means no limit
+func pageLimit(pageSize, limit, scanned int64) *int64 {
+	n := pageSize
+	if limit > 0 && (n == 0 || limit-scanned < n) {
+		n = limit - scanned
+	}
+	if n <= 0 {
+		return nil
+	}
+	return aws.Int64(n)
+}
+
+func queryInput(table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, o Options) (*dynamodb.QueryInput, error) {
+	parts := exprParts{keyCondition: &keyCond}
+	if isSet(filter) {
+		parts.filter = &filter
+	}
+	if len(q.ProjectionAttrs) > 0 {
+		p := projection(q.ProjectionAttrs)
+		parts.projection = &p
+	}
+	expr, err := parts.build()
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.QueryInput{
+		ExclusiveStartKey:         q.StartKey,
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     pageLimit(o.PageSize, q.Limit, 0),
+		ProjectionExpression:      expr.Projection(),
+		ScanIndexForward:          q.ScanIndexForward,
+		ReturnConsumedCapacity:    aws.String(o.ReturnConsumedCapacity),
+		TableName:                 aws.String(table),
+	}
+	if q.ConsistentRead || o.ConsistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	if q.IndexName != "" {
+		input.IndexName = aws.String(q.IndexName)
+	}
+	if o.SinglePage != nil && *o.SinglePage != nil {
+		input.ExclusiveStartKey = *o.SinglePage
+	}
+	return input, nil
+}
+
+func projection(attrs []string) expression.ProjectionBuilder {
+	names := make([]expression.NameBuilder, 0, len(attrs))
+	for _, attr := range attrs {
+		names = append(names, expression.Name(attr))
+	}
+	return expression.NamesList(names[0], names[1:]...)
+}
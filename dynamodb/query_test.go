@@ -0,0 +1,138 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// testSparseIndex is a table whose sparse index gsi1 only holds the records
+// with a gsi1pk attribute, it answers queries of the index with an equality
+// key condition and a MissingOr(name, name > value) filter
+type testSparseIndex struct {
+	t       *testing.T
+	records []map[string]*dynamodb.AttributeValue
+}
+
+var (
+	testKeyEqual  = regexp.MustCompile(`^(#\w+) = (:\w+)$`)
+	testMissingOr = regexp.MustCompile(`^\(attribute_not_exists \((#\w+)\)\) OR \((#\w+) > (:\w+)\)$`)
+)
+
+func (s *testSparseIndex) handle(op string, body []byte) (int, interface{}) {
+	var input dynamodb.QueryInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		s.t.Error(err)
+	}
+	key := testKeyEqual.FindStringSubmatch(aws.StringValue(input.KeyConditionExpression))
+	filter := testMissingOr.FindStringSubmatch(aws.StringValue(input.FilterExpression))
+	if op != "Query" || aws.StringValue(input.IndexName) != "gsi1" || key == nil || filter == nil {
+		s.t.Errorf("unexpected %s of %q: %q filtered by %q", op, aws.StringValue(input.IndexName), aws.StringValue(input.KeyConditionExpression), aws.StringValue(input.FilterExpression))
+		return http.StatusBadRequest, fakeError("ValidationException")
+	}
+	names, values := input.ExpressionAttributeNames, input.ExpressionAttributeValues
+	keyName, filterName := aws.StringValue(names[key[1]]), aws.StringValue(names[filter[1]])
+	limit, _ := strconv.ParseFloat(aws.StringValue(values[filter[3]].N), 64)
+	var out dynamodb.QueryOutput
+	for _, record := range s.records {
+		// records without the index key are not in the index
+		if record[keyName] == nil || !AttributeEqual(record[keyName], values[key[2]]) {
+			continue
+		}
+		if v := record[filterName]; v != nil {
+			if n, _ := strconv.ParseFloat(aws.StringValue(v.N), 64); n <= limit {
+				continue
+			}
+		}
+		out.Items = append(out.Items, record)
+	}
+	return http.StatusOK, out
+}
+
+func TestSparseIndexInput(t *testing.T) {
+	record := func(id, gsi1pk, expires string) map[string]*dynamodb.AttributeValue {
+		r := map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}}
+		if gsi1pk != "" {
+			r["gsi1pk"] = &dynamodb.AttributeValue{S: aws.String(gsi1pk)}
+		}
+		if expires != "" {
+			r["expires"] = &dynamodb.AttributeValue{N: aws.String(expires)}
+		}
+		return r
+	}
+	store := &testSparseIndex{t: t, records: []map[string]*dynamodb.AttributeValue{
+		record("fresh", "open", "200"),
+		record("forever", "open", ""),
+		record("expired", "open", "50"),
+		record("closed", "closed", ""),
+		record("unindexed", "", ""),
+		record("unindexed-fresh", "", "300"),
+	}}
+	keyCond := expression.Key("gsi1pk").Equal(expression.Value("open"))
+	filter := MissingOr("expires", expression.Name("expires").GreaterThan(expression.Value(100)))
+	input, err := SparseIndexInput("t", "gsi1", keyCond, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.StringValue(input.IndexName); got != "gsi1" {
+		t.Errorf("got index %q, want gsi1", got)
+	}
+	client := newFakeClient(t, store.handle)
+	var ids []string
+	err = client.QueryPages(input, func(page *dynamodb.QueryOutput, last bool) bool {
+		for _, item := range page.Items {
+			ids = append(ids, aws.StringValue(item["id"].S))
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(ids)
+	// the unindexed records never match, forever has no expires and passes MissingOr
+	if want := []string{"forever", "fresh"}; len(ids) != 2 || ids[0] != want[0] || ids[1] != want[1] {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestStartKeyFrom(t *testing.T) {
+	describes := 0
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		describes++
+		return http.StatusOK, dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+			KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{{
+				IndexName: aws.String("gsi1"),
+				KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("gsi1pk"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+			}},
+		}}
+	})
+	last := struct {
+		ID     string `dynamodbav:"id"`
+		GSI1PK string `dynamodbav:"gsi1pk"`
+		Name   string `dynamodbav:"name"`
+	}{"a", "open", "x"}
+	var summary Summary
+	key, err := StartKeyFrom(context.Background(), client, "t", "gsi1", last, WithSummary(&summary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{gsi1pk="open", id="a"}`; KeyString(key) != want {
+		t.Errorf("got %s, want %s", KeyString(key), want)
+	}
+	if summary.Operation != "StartKeyFrom" || describes != 1 {
+		t.Errorf("got summary %+v after %d DescribeTable", summary, describes)
+	}
+	if _, err := StartKeyFrom(context.Background(), client, "t", "gsi2", last); err == nil || !strings.Contains(err.Error(), `no index "gsi2"`) {
+		t.Errorf("got %v, want an error naming gsi2", err)
+	}
+}
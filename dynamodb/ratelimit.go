@@ -0,0 +1,68 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// RateLimit counts one hit in the fixed window of the record with key and
+// reports the count so far and whether it is above limit. The window starts
+// at the first hit and lasts window, its end is stored as Unix time in
+// expiresAttr, which can be the TTL attribute of the table so idle counters
+// get reaped. An expired window is reset to a count of 1 atomically.
+// countAttr: numeric counter attribute
+func RateLimit(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, countAttr, expiresAttr string, window time.Duration, limit int64, opts ...Option) (count int64, exceeded bool, err error) {
+	ctx, op := startOp(ctx, "RateLimit", table, opts)
+	defer op.end(&err)
+	// The window is either running, then the counter is incremented, or
+	// missing or expired, then it is reset. Another client can reset it in
+	// between the two conditional updates, hence the retry.
+	for attempt := 0; attempt < 3; attempt++ {
+		now := time.Now()
+		count, err := rateIncrement(ctx, client, table, key, countAttr, expiresAttr, now, op)
+		if err == nil {
+			return count, count > limit, nil
+		}
+		if !isConditionFailed(err) {
+			return 0, false, err
+		}
+		err = rateReset(ctx, client, table, key, countAttr, expiresAttr, now, window, op)
+		if err == nil {
+			return 1, 1 > limit, nil
+		}
+		if !isConditionFailed(err) {
+			return 0, false, err
+		}
+	}
+	return 0, false, fmt.Errorf("dynamodb: %s kept changing while counting %s", table, countAttr)
+}
+
+// rateIncrement adds one to the counter of a window still running at now
+func rateIncrement(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, countAttr, expiresAttr string, now time.Time, op *operation) (int64, error) {
+	update := expression.Add(expression.Name(countAttr), expression.Value(1))
+	condition := expression.Name(expiresAttr).GreaterThan(expression.Value(now.Unix()))
+	attributes, err := updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueUpdatedNew, op)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := dynamodbattribute.Unmarshal(attributes[countAttr], &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// rateReset starts a new window at now unless one is still running
+func rateReset(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, countAttr, expiresAttr string, now time.Time, window time.Duration, op *operation) error {
+	update := expression.Set(expression.Name(countAttr), expression.Value(1)).
+		Set(expression.Name(expiresAttr), expression.Value(now.Add(window).Unix()))
+	condition := expression.Name(expiresAttr).AttributeNotExists().
+		Or(expression.Name(expiresAttr).LessThanEqual(expression.Value(now.Unix())))
+	_, err := updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	return err
+}
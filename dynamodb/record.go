@@ -0,0 +1,158 @@
+package dynamodb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Recording is one request of a client built with WithRecording and the
+// response DynamoDB sent
+type Recording struct {
+	// Operation is the DynamoDB API action, e.g. Query
+	Operation string `json:"operation"`
+	// Request is the JSON body of the request
+	Request json.RawMessage `json:"request"`
+	// Status is the HTTP status code of the response
+	Status int `json:"status"`
+	// Response is the JSON body of the response, an error for a status >= 400
+	Response json.RawMessage `json:"response"`
+}
+
+// recorder is the HTTP transport of WithRecording
+type recorder struct {
+	mu   sync.Mutex
+	w    io.Writer
+	next http.RoundTripper
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	next := r.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	line, err := json.Marshal(Recording{Operation: operationName(req), Request: rawJSON(body), Status: resp.StatusCode, Response: rawJSON(data)})
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NewReplayClient func returns a client that serves the recordings written
+// with WithRecording in their order instead of calling DynamoDB, so tests
+// replay captured traffic deterministically. A request for another operation
+// than the next recording, or past the last one, fails with an awserr.Error
+// of code ReplayException. The client retries like NewClient, so recorded
+// retries are replayed too. Requests aren't compared beyond their operation
+// because some helpers put timestamps in them.
+func NewReplayClient(r io.Reader) (*dynamodb.DynamoDB, error) {
+	var recordings []Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*MaxItemSize)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("dynamodb: recording %d: %w", len(recordings)+1, err)
+		}
+		recordings = append(recordings, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewClient("us-east-1", WithEndpoint("http://replay.invalid"), WithStaticCredentials("replay", "replay", ""), WithTransport(&replayer{recordings: recordings}))
+}
+
+// replayer is the HTTP transport of NewReplayClient
+type replayer struct {
+	mu         sync.Mutex
+	recordings []Recording
+	next       int
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	name := operationName(req)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next >= len(r.recordings) {
+		return replayError(req, fmt.Sprintf("no recording left for %s", name))
+	}
+	rec := r.recordings[r.next]
+	if rec.Operation != name {
+		return replayError(req, fmt.Sprintf("recording %d is %s, got %s", r.next+1, rec.Operation, name))
+	}
+	r.next++
+	return replayResponse(req, rec.Status, rec.Response), nil
+}
+
+// replayError fails a request with a ReplayException the SDK doesn't retry,
+// unlike a transport error
+func replayError(req *http.Request, message string) (*http.Response, error) {
+	body, err := json.Marshal(map[string]string{"__type": "ReplayException", "message": message})
+	if err != nil {
+		return nil, err
+	}
+	return replayResponse(req, http.StatusBadRequest, body), nil
+}
+
+func replayResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        http.StatusText(status),
+		Header:        http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// operationName returns the API action of a DynamoDB request from its
+// X-Amz-Target header, e.g. DynamoDB_20120810.Query
+func operationName(req *http.Request) string {
+	target := req.Header.Get("X-Amz-Target")
+	return target[strings.LastIndex(target, ".")+1:]
+}
+
+// rawJSON returns data as a JSON value, null when it is empty
+func rawJSON(data []byte) json.RawMessage {
+	if len(bytes.TrimSpace(data)) == 0 || !json.Valid(data) {
+		return json.RawMessage("null")
+	}
+	return data
+}
@@ -0,0 +1,93 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// BackoffConfig controls retries with exponential backoff and full jitter
+// Base: delay before the first retry
+// Max: upper bound of a single delay
+// MaxAttempts: number of attempts including the first one
+type BackoffConfig struct {
+	Base        time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// DefaultBackoff is used by the helpers that retry unless WithBackoff is given
+var DefaultBackoff = BackoffConfig{Base: 50 * time.Millisecond, Max: 5 * time.Second, MaxAttempts: 10}
+
+// delay returns a random delay for the given retry, starting at 1
+func (c BackoffConfig) delay(retry int) time.Duration {
+	d := c.Base << uint(retry-1)
+	if d <= 0 || d > c.Max {
+		d = c.Max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isThrottle reports whether err means DynamoDB rejected the request for
+// lack of capacity
+func isThrottle(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException, dynamodb.ErrCodeRequestLimitExceeded, "ThrottlingException":
+		return true
+	}
+	return false
+}
+
+// Retry calls fn until it succeeds, returns an error that isn't retryable or
+// cfg.MaxAttempts is reached, sleeping with cfg's jittered backoff in between.
+// Throttling, internal server errors and transaction conflicts are retried,
+// so fn can wrap a whole read-modify-write sequence. It returns the last error
+// of fn or the error of ctx.
+func Retry(ctx context.Context, fn func() error, cfg BackoffConfig) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isRetryable(err) || attempt >= cfg.MaxAttempts {
+			return err
+		}
+		if err := sleep(ctx, cfg.delay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// isRetryable reports whether repeating the request that failed with err can
+// succeed
+func isRetryable(err error) bool {
+	if isThrottle(err) {
+		return true
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	switch aerr.Code() {
+	case dynamodb.ErrCodeInternalServerError, dynamodb.ErrCodeTransactionConflictException, "ServiceUnavailable":
+		return true
+	}
+	return false
+}
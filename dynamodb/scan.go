@@ -0,0 +1,231 @@
+package dynamodb
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// ScanRecords returns every record of the table matching filter
+// filter: an unset expression.ConditionBuilder scans without a filter
+func ScanRecords(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "ScanRecords", table, opts)
+	defer op.end(&err)
+	output, _, err := scanAll(ctx, client, table, filter, op)
+	return output, err
+}
+
+// ScanStats sums the ScannedCount and Count of the pages of a scan
+// Scanned: items read, and charged, before filtering
+// Matched: items matching the filter
+type ScanStats struct {
+	Scanned int64
+	Matched int64
+}
+
+// Fraction returns the share of scanned items that matched, 1 for an empty scan
+func (s ScanStats) Fraction() float64 {
+	if s.Scanned == 0 {
+		return 1
+	}
+	return float64(s.Matched) / float64(s.Scanned)
+}
+
+// lowScanFraction is the matched fraction below which scans log a warning
+const lowScanFraction = 0.1
+
+// ScanRecordsWithStats scans like ScanRecords and also reports how selective
+// filter was. A low fraction means most of the read capacity is spent on
+// records thrown away, which an index avoids.
+func ScanRecordsWithStats(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, _ ScanStats, err error) {
+	ctx, op := startOp(ctx, "ScanRecordsWithStats", table, opts)
+	defer op.end(&err)
+	return scanAll(ctx, client, table, filter, op)
+}
+
+// scanAll reads every page of a scan, it logs a warning when the filter
+// matched less than lowScanFraction of the scanned items
+func scanAll(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, op *operation) ([]map[string]*dynamodb.AttributeValue, ScanStats, error) {
+	var stats ScanStats
+	input, err := scanInput(table, filter, op.opts)
+	if err != nil {
+		return nil, stats, err
+	}
+	var output []map[string]*dynamodb.AttributeValue
+	for {
+		if err := op.nextPage(); err != nil {
+			return nil, stats, err
+		}
+		result, err := client.ScanWithContext(ctx, input)
+		if err != nil {
+			return nil, stats, err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		output = append(output, result.Items...)
+		stats.Scanned += aws.Int64Value(result.ScannedCount)
+		stats.Matched += aws.Int64Value(result.Count)
+		if op.lastPage(result.LastEvaluatedKey) {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	op.addItems(len(output))
+	if isSet(filter) && stats.Fraction() < lowScanFraction {
+		op.opts.logf("dynamodb: scan of %s matched %d of %d items, consider an index", table, stats.Matched, stats.Scanned)
+	}
+	return output, stats, nil
+}
+
+// ScanTyped scans the whole table and unmarshals every record into T
+func ScanTyped[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, opts ...Option) (_ []T, err error) {
+	ctx, op := startOp(ctx, "ScanTyped", table, opts)
+	defer op.end(&err)
+	it, err := newScanIterator[T](ctx, client, table, filter, op)
+	if err != nil {
+		return nil, err
+	}
+	var output []T
+	for it.Next() {
+		output = append(output, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// ScanIterator yields the records of a table as T, one page is fetched at a time
+type ScanIterator[T any] struct {
+	ctx    context.Context
+	client *dynamodb.DynamoDB
+	input  *dynamodb.ScanInput
+	op     *operation
+	// owned is set when the iterator ends op, ScanTyped ends its own
+	owned bool
+	page  []map[string]*dynamodb.AttributeValue
+	value T
+	last  bool
+	done  bool
+	err   error
+}
+
+// NewScanIterator func returns an iterator over the records of table matching
+// filter. Its pages count as one operation, which ends when Next returns false.
+func NewScanIterator[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, opts ...Option) (_ *ScanIterator[T], err error) {
+	ctx, op := startOp(ctx, "ScanIterator", table, opts)
+	it, err := newScanIterator[T](ctx, client, table, filter, op)
+	if err != nil {
+		op.end(&err)
+		return nil, err
+	}
+	it.owned = true
+	return it, nil
+}
+
+func newScanIterator[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, op *operation) (*ScanIterator[T], error) {
+	input, err := scanInput(table, filter, op.opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ScanIterator[T]{ctx: ctx, client: client, input: input, op: op}, nil
+}
+
+// Next advances to the next record, it returns false when the scan is
+// finished or an error occurred
+func (it *ScanIterator[T]) Next() bool {
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		if it.last {
+			it.finish(nil)
+			return false
+		}
+		if err := it.scan(); err != nil {
+			it.finish(err)
+			return false
+		}
+	}
+	item := it.page[0]
+	it.page = it.page[1:]
+	var value T
+	if err := unmarshalItem(item, &value, it.op.opts.Coercions); err != nil {
+		it.finish(&ItemError{Key: it.op.itemKey(it.ctx, it.client, aws.StringValue(it.input.TableName), item), Err: err})
+		return false
+	}
+	it.value = value
+	return true
+}
+
+// Value returns the current record
+func (it *ScanIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, an unmarshal failure
+// is reported as *ItemError
+func (it *ScanIterator[T]) Err() error {
+	return it.err
+}
+
+// scan fetches the next page, the last one is reported by op.lastPage
+func (it *ScanIterator[T]) scan() error {
+	if err := it.op.nextPage(); err != nil {
+		return err
+	}
+	result, err := it.client.ScanWithContext(it.ctx, it.input)
+	if err != nil {
+		return err
+	}
+	it.op.addItems(len(result.Items))
+	it.op.addCapacity(result.ConsumedCapacity)
+	it.page = result.Items
+	it.last = it.op.lastPage(result.LastEvaluatedKey)
+	it.input.ExclusiveStartKey = result.LastEvaluatedKey
+	return nil
+}
+
+// finish stops the iteration with err and ends the operation it owns
+func (it *ScanIterator[T]) finish(err error) {
+	if it.owned {
+		it.op.end(&err)
+	}
+	it.err = err
+	it.done = true
+}
+
+func scanInput(table string, filter expression.ConditionBuilder, o Options) (*dynamodb.ScanInput, error) {
+	input := &dynamodb.ScanInput{
+		Limit:                  pageLimit(o.PageSize, 0, 0),
+		ReturnConsumedCapacity: aws.String(o.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	if o.ConsistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	if o.Index != "" {
+		input.IndexName = aws.String(o.Index)
+	}
+	if o.SinglePage != nil {
+		input.ExclusiveStartKey = *o.SinglePage
+	}
+	if !isSet(filter) {
+		return input, nil
+	}
+	expr, err := exprParts{filter: &filter}.build()
+	if err != nil {
+		return nil, err
+	}
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.FilterExpression = expr.Filter()
+	return input, nil
+}
+
+// isSet reports whether the condition was built, the zero value means no condition
+func isSet(condition expression.ConditionBuilder) bool {
+	return !reflect.DeepEqual(condition, expression.ConditionBuilder{})
+}
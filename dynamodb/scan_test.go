@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestScanIteratorItemErrorKey(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}, "n": {S: aws.String("not a number")}}
+	tests := []struct {
+		name     string
+		describe bool
+		want     map[string]*dynamodb.AttributeValue
+	}{
+		{"described", true, map[string]*dynamodb.AttributeValue{"id": item["id"]}},
+		{"describe fails", false, item},
+	}
+	for _, tt := range tests {
+		client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+			switch op {
+			case "DescribeTable":
+				if !tt.describe {
+					return http.StatusBadRequest, fakeError("AccessDeniedException")
+				}
+				return http.StatusOK, dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+					KeySchema: []*dynamodb.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)}},
+				}}
+			}
+			return http.StatusOK, dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}
+		})
+		_, err := ScanTyped[struct{ N int }](context.Background(), client, "t", expression.ConditionBuilder{})
+		var ierr *ItemError
+		if !errors.As(err, &ierr) {
+			t.Fatalf("%s: got %v, want *ItemError", tt.name, err)
+		}
+		if KeyString(ierr.Key) != KeyString(tt.want) {
+			t.Errorf("%s: got key %s, want %s", tt.name, KeyString(ierr.Key), KeyString(tt.want))
+		}
+	}
+}
+
+// fakePages answers Scan and Query requests with pages items per page, the
+// ExclusiveStartKey id of a request is the number of the page it asks for
+func fakePages(t *testing.T, pages int, requests *int) fakeHandler {
+	return func(op string, body []byte) (int, interface{}) {
+		var input struct {
+			ExclusiveStartKey map[string]*dynamodb.AttributeValue
+		}
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Error(err)
+		}
+		*requests++
+		page := 0
+		if k := input.ExclusiveStartKey["id"]; k != nil {
+			page, _ = strconv.Atoi(aws.StringValue(k.N))
+		}
+		item := map[string]*dynamodb.AttributeValue{"id": {S: aws.String(fmt.Sprint("item", page))}}
+		out := dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}, ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(0.5)}}
+		if page+1 < pages {
+			out.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"id": {N: aws.String(strconv.Itoa(page + 1))}}
+		}
+		return http.StatusOK, out
+	}
+}
+
+func TestScanIteratorMaxPages(t *testing.T) {
+	requests := 0
+	client := newFakeClient(t, fakePages(t, 3, &requests))
+	it, err := NewScanIterator[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, WithMaxPages(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if !errors.Is(it.Err(), ErrPageLimitExceeded) {
+		t.Fatalf("got %v, want ErrPageLimitExceeded", it.Err())
+	}
+	if n != 2 || requests != 2 {
+		t.Errorf("got %d records in %d requests, want 2 in 2", n, requests)
+	}
+}
+
+func TestScanIteratorSinglePage(t *testing.T) {
+	requests := 0
+	client := newFakeClient(t, fakePages(t, 3, &requests))
+	var next map[string]*dynamodb.AttributeValue
+	for page := 0; page < 3; page++ {
+		it, err := NewScanIterator[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, WithSinglePage(&next))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []interface{}
+		for it.Next() {
+			got = append(got, it.Value()["id"])
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprint("item", page); len(got) != 1 || got[0] != want {
+			t.Errorf("page %d: got %v, want [%s]", page, got, want)
+		}
+		if (next == nil) != (page == 2) {
+			t.Errorf("page %d: got next %v", page, next)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
+
+func TestScanTypedSummary(t *testing.T) {
+	requests := 0
+	client := newFakeClient(t, fakePages(t, 3, &requests))
+	var summary Summary
+	records, err := ScanTyped[map[string]interface{}](context.Background(), client, "t", expression.ConditionBuilder{}, WithSummary(&summary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if summary.Operation != "ScanTyped" || summary.Items != 3 || summary.Pages != 3 || summary.Capacity != 1.5 {
+		t.Errorf("got %+v, want ScanTyped with 3 items, 3 pages and 1.5 capacity", summary)
+	}
+}
@@ -0,0 +1,70 @@
+package dynamodb
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// SchemaInfo describes one attribute across a set of items
+// Types: number of items holding the attribute per type, e.g. "S" or "N"
+// Count: number of items holding the attribute
+// Presence: fraction of the items holding the attribute
+type SchemaInfo struct {
+	Types    map[string]int
+	Count    int
+	Presence float64
+}
+
+// Mixed reports whether the attribute is stored with more than one type
+func (s SchemaInfo) Mixed() bool {
+	return len(s.Types) > 1
+}
+
+// InspectSchema reports per top-level attribute which types appear in items
+// and how often, to spot inconsistent data such as an attribute stored as S
+// in some items and N in others
+func InspectSchema(items []map[string]*dynamodb.AttributeValue) map[string]SchemaInfo {
+	output := map[string]SchemaInfo{}
+	for _, item := range items {
+		for name, v := range item {
+			info, ok := output[name]
+			if !ok {
+				info.Types = map[string]int{}
+			}
+			info.Types[attributeType(v)]++
+			info.Count++
+			output[name] = info
+		}
+	}
+	for name, info := range output {
+		info.Presence = float64(info.Count) / float64(len(items))
+		output[name] = info
+	}
+	return output
+}
+
+// attributeType returns the DynamoDB type name of v
+func attributeType(v *dynamodb.AttributeValue) string {
+	switch {
+	case v == nil || v.NULL != nil:
+		return "NULL"
+	case v.S != nil:
+		return "S"
+	case v.N != nil:
+		return "N"
+	case v.B != nil:
+		return "B"
+	case v.BOOL != nil:
+		return "BOOL"
+	case v.SS != nil:
+		return "SS"
+	case v.NS != nil:
+		return "NS"
+	case v.BS != nil:
+		return "BS"
+	case v.L != nil:
+		return "L"
+	case v.M != nil:
+		return "M"
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// SetMember is the member type of a String Set or a Number Set
+type SetMember interface {
+	string | int64 | float64
+}
+
+// RemoveFromSet deletes members from the set attr and returns how many
+// members are left. Removing the last members removes the attribute in the
+// same update, since DynamoDB stores no empty sets, whereas writing back a
+// set emptied client side fails with a ValidationException. Members not in
+// the set are ignored, a missing record or attribute leaves 0 members unless
+// WithConditionError makes it return ErrConditionFailed.
+func RemoveFromSet[T SetMember](ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, attr string, members []T, opts ...Option) (_ int, err error) {
+	ctx, op := startOp(ctx, "RemoveFromSet", table, opts)
+	defer op.end(&err)
+	if len(members) == 0 {
+		return 0, fmt.Errorf("dynamodb: no members to remove from %s", attr)
+	}
+	update := expression.Delete(expression.Name(attr), expression.Value(rawValue{setOf(members)}))
+	// without the condition a missing record would be created with its key
+	condition := expression.Name(attr).AttributeExists()
+	attrs, err := updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueUpdatedNew, op)
+	if isConditionFailed(err) && !op.opts.ConditionError {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	left := attrs[attr]
+	if left == nil {
+		return 0, nil
+	}
+	return len(left.SS) + len(left.NS), nil
+}
+
+// setOf returns members as a String Set or a Number Set
+func setOf[T SetMember](members []T) *dynamodb.AttributeValue {
+	switch m := any(members).(type) {
+	case []string:
+		return &dynamodb.AttributeValue{SS: aws.StringSlice(m)}
+	case []int64:
+		ns := make([]*string, len(m))
+		for i, n := range m {
+			ns[i] = aws.String(strconv.FormatInt(n, 10))
+		}
+		return &dynamodb.AttributeValue{NS: ns}
+	case []float64:
+		ns := make([]*string, len(m))
+		for i, n := range m {
+			ns[i] = aws.String(strconv.FormatFloat(n, 'f', -1, 64))
+		}
+		return &dynamodb.AttributeValue{NS: ns}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package dynamodb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// LowerSuffix is appended to an attribute name to name its lowercased shadow
+const LowerSuffix = "_lower"
+
+// ShadowName returns the name of the lowercased shadow of attr
+func ShadowName(attr string) string {
+	return attr + LowerSuffix
+}
+
+// AddLowercaseShadows returns a copy of item with a lowercased copy of every
+// string attribute in attrs under its ShadowName, missing and non-string
+// attributes are skipped. item itself is left unchanged.
+func AddLowercaseShadows(item map[string]*dynamodb.AttributeValue, attrs ...string) map[string]*dynamodb.AttributeValue {
+	output := make(map[string]*dynamodb.AttributeValue, len(item)+len(attrs))
+	for name, v := range item {
+		output[name] = v
+	}
+	for _, attr := range attrs {
+		v, ok := item[attr]
+		if !ok || v == nil || v.S == nil {
+			continue
+		}
+		output[ShadowName(attr)] = &dynamodb.AttributeValue{S: aws.String(strings.ToLower(*v.S))}
+	}
+	return output
+}
+
+// WriteRecordCaseInsensitive writes the record like WriteRecord together with
+// the lowercased shadows of attrs, so they can be searched with
+// QueryBeginsWithCaseInsensitive
+func WriteRecordCaseInsensitive(ctx context.Context, client *dynamodb.DynamoDB, data Payload, table string, attrs []string, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "WriteRecordCaseInsensitive", table, opts)
+	defer op.end(&err)
+	item, err := data.Payload()
+	if err != nil {
+		return err
+	}
+	item = AddLowercaseShadows(item, attrs...)
+	if err := validate(item); err != nil {
+		return err
+	}
+	input := &dynamodb.PutItemInput{
+		Item:                   item,
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TableName:              aws.String(table),
+	}
+	op.write = true
+	result, err := client.PutItemWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	op.addItems(1)
+	op.touch(item)
+	op.addCapacity(result.ConsumedCapacity)
+	return nil
+}
+
+// QueryBeginsWithCaseInsensitive returns the records of a partition whose attr
+// begins with prefix ignoring case. The shadow of attr written by
+// WriteRecordCaseInsensitive must be the sort key of the table or index.
+// index: DynamoDB index name, empty for the base table
+// key: partition key name
+// value: partition key value
+func QueryBeginsWithCaseInsensitive(ctx context.Context, client *dynamodb.DynamoDB, table, index, key, value, attr, prefix string, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryBeginsWithCaseInsensitive", table, opts)
+	defer op.end(&err)
+	keyCondition := expression.Key(key).Equal(expression.Value(value)).
+		And(expression.Key(ShadowName(attr)).BeginsWith(strings.ToLower(prefix)))
+	return query(ctx, client, table, keyCondition, expression.ConditionBuilder{}, QueryOptions{IndexName: index}, op)
+}
@@ -0,0 +1,33 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestWriteRecordCaseInsensitiveKeepsPayload(t *testing.T) {
+	var written map[string]*dynamodb.AttributeValue
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		var input dynamodb.PutItemInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Error(err)
+		}
+		written = input.Item
+		return http.StatusOK, dynamodb.PutItemOutput{}
+	})
+	record := testPayload{"id": {S: aws.String("a")}, "name": {S: aws.String("Alice")}}
+	if err := WriteRecordCaseInsensitive(context.Background(), client, record, "t", []string{"name"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.StringValue(written[ShadowName("name")].S); got != "alice" {
+		t.Errorf("got shadow %q, want alice", got)
+	}
+	if _, ok := record[ShadowName("name")]; ok || len(record) != 2 {
+		t.Errorf("the payload was modified: %v", record)
+	}
+}
@@ -0,0 +1,69 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// plainSet matches one SET action assigning a value to a top-level attribute
+var plainSet = regexp.MustCompile(`^(#\w+) = (:\w+)$`)
+
+// checkUpdateSize reads the item with key and returns ErrItemTooLarge when
+// the update would push it over MaxItemSize
+func checkUpdateSize(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, expr expression.Expression, op *operation) error {
+	old, err := getItem(ctx, client, table, key, true, op)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		old = key
+	}
+	if size := projectedSize(old, expr); size > MaxItemSize {
+		return fmt.Errorf("%w: about %d bytes after the update, the limit is %d", ErrItemTooLarge, size, MaxItemSize)
+	}
+	return nil
+}
+
+// projectedSize estimates the size of old after the update of expr. A plain
+// SET of a top-level attribute replaces its old value, every other value of
+// expr is assumed to add to the item and REMOVE to free nothing, so the
+// estimate errs on the large side.
+func projectedSize(old map[string]*dynamodb.AttributeValue, expr expression.Expression) int {
+	names, values := expr.Names(), expr.Values()
+	size := ItemSize(old)
+	used := map[string]bool{}
+	for _, line := range strings.Split(aws.StringValue(expr.Update()), "\n") {
+		if !strings.HasPrefix(line, "SET ") {
+			continue
+		}
+		for _, action := range strings.Split(strings.TrimPrefix(line, "SET "), ", ") {
+			m := plainSet.FindStringSubmatch(action)
+			if m == nil {
+				continue
+			}
+			name := aws.StringValue(names[m[1]])
+			size += len(name) + valueSize(values[m[2]])
+			if v, ok := old[name]; ok {
+				size -= len(name) + valueSize(v)
+			}
+			used[m[2]] = true
+		}
+	}
+	for placeholder, v := range values {
+		if !used[placeholder] {
+			size += valueSize(v)
+		}
+	}
+	for _, name := range names {
+		if _, ok := old[aws.StringValue(name)]; !ok {
+			size += len(aws.StringValue(name))
+		}
+	}
+	return size
+}
@@ -0,0 +1,40 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// DeletedAtAttr holds the Unix time a record was soft deleted at
+const DeletedAtAttr = "deletedAt"
+
+// SoftDelete marks the record as deleted now and sets its TTL attribute so
+// DynamoDB removes it after retention. It reports whether the record existed
+// and wasn't deleted already, QueryActive hides it right away.
+// ttlAttr: TTL attribute configured on the table
+func SoftDelete(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, ttlAttr string, retention time.Duration, opts ...Option) (_ bool, err error) {
+	ctx, op := startOp(ctx, "SoftDelete", table, opts)
+	defer op.end(&err)
+	now := time.Now()
+	update := expression.Set(expression.Name(DeletedAtAttr), expression.Value(now.Unix())).
+		Set(expression.Name(ttlAttr), expression.Value(now.Add(retention).Unix()))
+	condition := expression.Name(firstKeyName(key)).AttributeExists().
+		And(expression.Name(DeletedAtAttr).AttributeNotExists())
+	_, err = updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	if isConditionFailed(err) && !op.opts.ConditionError {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// QueryActive returns the records matching keyCond and filter like Query,
+// leaving out the ones marked by SoftDelete
+// filter: an unset expression.ConditionBuilder only hides deleted records
+func QueryActive(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "QueryActive", table, opts)
+	defer op.end(&err)
+	return query(ctx, client, table, keyCond, All(filter, WhereNotExists(DeletedAtAttr)), q, op)
+}
@@ -0,0 +1,113 @@
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// QueryChan pushes the records matching keyCond and filter to the returned
+// channel as pages arrive, and closes it when the query is done. A failure,
+// including the cancellation of ctx, is sent on the error channel, which is
+// closed after the item channel.
+func QueryChan(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, opts ...Option) (<-chan map[string]*dynamodb.AttributeValue, <-chan error) {
+	items := make(chan map[string]*dynamodb.AttributeValue)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(items)
+		if err := queryChan(ctx, client, table, keyCond, filter, items, opts); err != nil {
+			errs <- err
+		}
+	}()
+	return items, errs
+}
+
+func queryChan(ctx context.Context, client *dynamodb.DynamoDB, table string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, items chan<- map[string]*dynamodb.AttributeValue, opts []Option) (err error) {
+	ctx, op := startOp(ctx, "QueryChan", table, opts)
+	defer op.end(&err)
+	input, err := queryInput(table, keyCond, filter, QueryOptions{}, op.opts)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := op.nextPage(); err != nil {
+			return err
+		}
+		result, err := client.QueryWithContext(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		for _, item := range keep(result.Items, op.opts.Predicate) {
+			select {
+			case items <- item:
+				op.addItems(1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if op.lastPage(result.LastEvaluatedKey) {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ScanChan pushes the records of table matching filter to the returned
+// channel like QueryChan, fetching the next page only once the consumer took
+// every record of the current one but the last buffer. A slow consumer thus
+// holds back the scan instead of records piling up in memory, at most one
+// page, which WithPageSize bounds, plus buffer records are held. Cancelling
+// ctx stops the scan before its next request.
+// buffer: capacity of the item channel
+func ScanChan(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, buffer int, opts ...Option) (<-chan map[string]*dynamodb.AttributeValue, <-chan error) {
+	items := make(chan map[string]*dynamodb.AttributeValue, buffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(items)
+		if err := scanChan(ctx, client, table, filter, items, opts); err != nil {
+			errs <- err
+		}
+	}()
+	return items, errs
+}
+
+func scanChan(ctx context.Context, client *dynamodb.DynamoDB, table string, filter expression.ConditionBuilder, items chan<- map[string]*dynamodb.AttributeValue, opts []Option) (err error) {
+	ctx, op := startOp(ctx, "ScanChan", table, opts)
+	defer op.end(&err)
+	input, err := scanInput(table, filter, op.opts)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := op.nextPage(); err != nil {
+			return err
+		}
+		result, err := client.ScanWithContext(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		op.addCapacity(result.ConsumedCapacity)
+		for _, item := range result.Items {
+			select {
+			case items <- item:
+				op.addItems(1)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if op.lastPage(result.LastEvaluatedKey) {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,208 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// TableEnv names the environment variable SetDefaultTable falls back to
+const TableEnv = "DYNAMODB_TABLE"
+
+// ErrNoDefaultTable is returned by DefaultTable when no default table is set
+var ErrNoDefaultTable = errors.New("dynamodb: no default table set")
+
+// Table binds a client to one table so calls don't repeat the table name
+type Table struct {
+	client *dynamodb.DynamoDB
+	name   string
+}
+
+// NewTable func returns a Table for name
+func NewTable(client *dynamodb.DynamoDB, name string) *Table {
+	return &Table{client: client, name: name}
+}
+
+// Name returns the table name
+func (t *Table) Name() string {
+	return t.name
+}
+
+// WriteRecord writes one record, see WriteRecord
+func (t *Table) WriteRecord(data Payload, opts ...Option) error {
+	return WriteRecord(t.client, data, t.name, opts...)
+}
+
+// Put writes a plain struct, see Put
+func (t *Table) Put(ctx context.Context, v interface{}, opts ...Option) error {
+	return Put(ctx, t.client, t.name, v, opts...)
+}
+
+// WriteRecords writes records in batches, see WriteRecords
+func (t *Table) WriteRecords(data []map[string]*dynamodb.AttributeValue, opts ...Option) error {
+	return WriteRecords(t.client, data, t.name, opts...)
+}
+
+// Query returns the matching records, see Query
+func (t *Table) Query(ctx context.Context, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) ([]map[string]*dynamodb.AttributeValue, error) {
+	return Query(ctx, t.client, t.name, keyCond, filter, q, opts...)
+}
+
+// Scan returns every matching record, see ScanRecords
+func (t *Table) Scan(ctx context.Context, filter expression.ConditionBuilder, opts ...Option) ([]map[string]*dynamodb.AttributeValue, error) {
+	return ScanRecords(ctx, t.client, t.name, filter, opts...)
+}
+
+// Get returns one record by key, see GetRecord
+func (t *Table) Get(ctx context.Context, key map[string]*dynamodb.AttributeValue, opts ...Option) (map[string]*dynamodb.AttributeValue, error) {
+	return GetRecord(ctx, t.client, t.name, key, opts...)
+}
+
+// BatchGet fetches records by key, see BatchGetRecords
+func (t *Table) BatchGet(ctx context.Context, keys []map[string]*dynamodb.AttributeValue, opts ...Option) ([]map[string]*dynamodb.AttributeValue, error) {
+	return BatchGetRecords(ctx, t.client, t.name, keys, opts...)
+}
+
+// AddNumber adds number to an attribute, see AddNumber
+func (t *Table) AddNumber(key map[string]*dynamodb.AttributeValue, name string, number int64, opts ...Option) error {
+	return AddNumber(t.client, t.name, key, name, number, opts...)
+}
+
+// Delete deletes a record, see DeleteRecord
+func (t *Table) Delete(ctx context.Context, key map[string]*dynamodb.AttributeValue, opts ...Option) (bool, error) {
+	return DeleteRecord(ctx, t.client, t.name, key, opts...)
+}
+
+var defaultTable *Table
+
+// SetDefaultTable sets the table returned by DefaultTable, an empty name
+// falls back to the DYNAMODB_TABLE environment variable.
+// It should be called before the helpers are used.
+func SetDefaultTable(client *dynamodb.DynamoDB, name string) {
+	if name == "" {
+		name = os.Getenv(TableEnv)
+	}
+	if name == "" {
+		defaultTable = nil
+		return
+	}
+	defaultTable = NewTable(client, name)
+}
+
+// DefaultTable returns the table set by SetDefaultTable, or ErrNoDefaultTable
+// so a missing configuration can't silently hit the wrong table
+func DefaultTable() (*Table, error) {
+	if defaultTable == nil {
+		return nil, ErrNoDefaultTable
+	}
+	return defaultTable, nil
+}
+
+// keyAttributes returns the names of the table's key attributes, followed
+// by those of index unless it is empty
+func keyAttributes(ctx context.Context, client *dynamodb.DynamoDB, table, index string) ([]string, error) {
+	result, err := client.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, err
+	}
+	schema := result.Table.KeySchema
+	if index != "" {
+		found := false
+		for _, gsi := range result.Table.GlobalSecondaryIndexes {
+			if aws.StringValue(gsi.IndexName) == index {
+				schema, found = append(schema, gsi.KeySchema...), true
+			}
+		}
+		for _, lsi := range result.Table.LocalSecondaryIndexes {
+			if aws.StringValue(lsi.IndexName) == index {
+				schema, found = append(schema, lsi.KeySchema...), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("dynamodb: table %s has no index %q", table, index)
+		}
+	}
+	var names []string
+	for _, k := range schema {
+		names = append(names, aws.StringValue(k.AttributeName))
+	}
+	return names, nil
+}
+
+// keyAttributes returns the names of the key attributes of table, and of
+// index unless it is empty, the key schema is described once per operation
+func (o *operation) keyAttributes(ctx context.Context, client *dynamodb.DynamoDB, table, index string) ([]string, error) {
+	k := table
+	if index != "" {
+		k += "/" + index
+	}
+	if names, ok := o.keyNames[k]; ok {
+		return names, nil
+	}
+	names, err := keyAttributes(ctx, client, table, index)
+	if err != nil {
+		return nil, err
+	}
+	if o.keyNames == nil {
+		o.keyNames = map[string][]string{}
+	}
+	o.keyNames[k] = names
+	return names, nil
+}
+
+// itemKey extracts the key attributes of item, it returns the whole item if
+// the key schema of the table can't be described
+func (o *operation) itemKey(ctx context.Context, client *dynamodb.DynamoDB, table string, item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	names, err := o.keyAttributes(ctx, client, table, "")
+	if err != nil {
+		return item
+	}
+	key := make(map[string]*dynamodb.AttributeValue, len(names))
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			key[name] = v
+		}
+	}
+	return key
+}
+
+// KeyString serializes key deterministically, e.g. {id="a", n=1}, to use it
+// as a map key
+func KeyString(key map[string]*dynamodb.AttributeValue) string {
+	return formatKey(key)
+}
+
+// formatKey renders a key as name=value pairs sorted by name
+func formatKey(key map[string]*dynamodb.AttributeValue) string {
+	var names []string
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%s", name, formatValue(key[name])))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func formatValue(v *dynamodb.AttributeValue) string {
+	switch {
+	case v == nil:
+		return "<nil>"
+	case v.S != nil:
+		return fmt.Sprintf("%q", *v.S)
+	case v.N != nil:
+		return *v.N
+	case v.B != nil:
+		return fmt.Sprintf("%x", v.B)
+	}
+	return strings.Join(strings.Fields(v.String()), " ")
+}
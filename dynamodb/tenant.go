@@ -0,0 +1,137 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// TenantTable is a Table of a multi-tenant single-table design whose
+// partition key values are prefixed with a tenant ID, e.g. acme#user-1. It adds
+// the prefix on writes, keys and queries, and strips it from the records it
+// returns unless KeepPrefix is set.
+//
+// The prefix only scopes the calls made through the TenantTable: it is not an
+// access control. Other clients, the Table of the same table and scans still
+// see every tenant. Enforce isolation with IAM policies on
+// dynamodb:LeadingKeys.
+type TenantTable struct {
+	client *dynamodb.DynamoDB
+	name   string
+	// PartitionKey is the partition key attribute name
+	PartitionKey string
+	// Tenant is the ID prepended to partition key values
+	Tenant string
+	// Key composes the prefix and the value, "#" separated by default
+	Key CompositeKey
+	// KeepPrefix returns records with the prefixed partition key as stored
+	KeepPrefix bool
+}
+
+// NewTenantTable func returns a TenantTable of tenant for table name
+// partitionKey: partition key attribute name, its values must be strings
+func NewTenantTable(client *dynamodb.DynamoDB, name, partitionKey, tenant string) *TenantTable {
+	return &TenantTable{client: client, name: name, PartitionKey: partitionKey, Tenant: tenant}
+}
+
+// Name returns the table name
+func (t *TenantTable) Name() string {
+	return t.name
+}
+
+// WriteRecord writes one record with the tenant prefix, see WriteRecord
+func (t *TenantTable) WriteRecord(data Payload, opts ...Option) error {
+	item, err := data.Payload()
+	if err != nil {
+		return err
+	}
+	prefixed, err := t.prefix(item)
+	if err != nil {
+		return err
+	}
+	return WriteRecord(t.client, payload(prefixed), t.name, opts...)
+}
+
+// Get returns the record with key, see GetRecord
+func (t *TenantTable) Get(ctx context.Context, key map[string]*dynamodb.AttributeValue, opts ...Option) (map[string]*dynamodb.AttributeValue, error) {
+	prefixed, err := t.prefix(key)
+	if err != nil {
+		return nil, err
+	}
+	item, err := GetRecord(ctx, t.client, t.name, prefixed, opts...)
+	if err != nil || item == nil {
+		return item, err
+	}
+	return t.strip(item), nil
+}
+
+// Query returns the records of the tenant partition pkVal matching sortCond
+// and filter, see Query
+// sortCond: a condition on the sort key, an unset expression.KeyConditionBuilder matches the whole partition
+func (t *TenantTable) Query(ctx context.Context, pkVal string, sortCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, q QueryOptions, opts ...Option) ([]map[string]*dynamodb.AttributeValue, error) {
+	keyCond := expression.Key(t.PartitionKey).Equal(expression.Value(t.Key.Join(t.Tenant, pkVal)))
+	if !reflect.DeepEqual(sortCond, expression.KeyConditionBuilder{}) {
+		keyCond = keyCond.And(sortCond)
+	}
+	items, err := Query(ctx, t.client, t.name, keyCond, filter, q, opts...)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]map[string]*dynamodb.AttributeValue, len(items))
+	for i, item := range items {
+		output[i] = t.strip(item)
+	}
+	return output, nil
+}
+
+// Delete deletes the record with key, see DeleteRecord
+func (t *TenantTable) Delete(ctx context.Context, key map[string]*dynamodb.AttributeValue, opts ...Option) (bool, error) {
+	prefixed, err := t.prefix(key)
+	if err != nil {
+		return false, err
+	}
+	return DeleteRecord(ctx, t.client, t.name, prefixed, opts...)
+}
+
+// prefix returns a copy of item with the tenant prepended to the partition key
+func (t *TenantTable) prefix(item map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	v, ok := item[t.PartitionKey]
+	if !ok || v == nil || v.S == nil {
+		return nil, fmt.Errorf("dynamodb: item has no string attribute %q", t.PartitionKey)
+	}
+	output := make(map[string]*dynamodb.AttributeValue, len(item))
+	for name, v := range item {
+		output[name] = v
+	}
+	output[t.PartitionKey] = &dynamodb.AttributeValue{S: aws.String(t.Key.Join(t.Tenant, *v.S))}
+	return output, nil
+}
+
+// strip returns a copy of item without the tenant in the partition key unless
+// KeepPrefix is set, item itself may be shared with a QueryCache
+func (t *TenantTable) strip(item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if t.KeepPrefix {
+		return item
+	}
+	parts, err := t.Key.Parse(item, t.PartitionKey)
+	if err != nil || len(parts) != 2 || parts[0] != t.Tenant {
+		return item
+	}
+	output := make(map[string]*dynamodb.AttributeValue, len(item))
+	for name, v := range item {
+		output[name] = v
+	}
+	output[t.PartitionKey] = &dynamodb.AttributeValue{S: aws.String(parts[1])}
+	return output
+}
+
+// payload passes a ready item as a Payload
+type payload map[string]*dynamodb.AttributeValue
+
+func (p payload) Payload() (map[string]*dynamodb.AttributeValue, error) {
+	return p, nil
+}
@@ -0,0 +1,40 @@
+package dynamodb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestTenantTableQueryKeepsCachedRecords(t *testing.T) {
+	requests := 0
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		requests++
+		return http.StatusOK, dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{{"pk": {S: aws.String("acme#user-1")}}}}
+	})
+	cache := NewQueryCache(time.Minute)
+	stripped := NewTenantTable(client, "t", "pk", "acme")
+	kept := NewTenantTable(client, "t", "pk", "acme")
+	kept.KeepPrefix = true
+	ctx := context.Background()
+	for i, tt := range []struct {
+		table *TenantTable
+		want  string
+	}{{stripped, "user-1"}, {stripped, "user-1"}, {kept, "acme#user-1"}} {
+		items, err := tt.table.Query(ctx, "user-1", expression.KeyConditionBuilder{}, expression.ConditionBuilder{}, QueryOptions{}, WithCache(cache))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || aws.StringValue(items[0]["pk"].S) != tt.want {
+			t.Errorf("query %d: got %v, want pk %s", i, items, tt.want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want the cache to serve 2 of 3", requests)
+	}
+}
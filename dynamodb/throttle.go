@@ -0,0 +1,108 @@
+package dynamodb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ThrottleTracker measures the share of requests rejected with
+// ProvisionedThroughputExceededException or another throttling error over a
+// rolling window, so applications can back off or scale before retries run out.
+// Every attempt counts, the ones the SDK retries included.
+type ThrottleTracker struct {
+	mu      sync.Mutex
+	width   time.Duration
+	buckets []throttleBucket
+}
+
+// throttleBucket counts the attempts of one slice of the window
+type throttleBucket struct {
+	start     time.Time
+	calls     int64
+	throttled int64
+}
+
+// NewThrottleTracker func returns a tracker over the given window, counted in
+// one second buckets. A window shorter than a second is one bucket, a window
+// of 0 or less is one second.
+func NewThrottleTracker(window time.Duration) *ThrottleTracker {
+	if window <= 0 {
+		window = time.Second
+	}
+	n := int(window / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return &ThrottleTracker{width: window / time.Duration(n), buckets: make([]throttleBucket, n)}
+}
+
+// observe counts one attempt finished at now
+func (t *ThrottleTracker) observe(now time.Time, throttled bool) {
+	start := now.Truncate(t.width)
+	t.mu.Lock()
+	b := &t.buckets[int(start.UnixNano()/int64(t.width))%len(t.buckets)]
+	if !b.start.Equal(start) {
+		*b = throttleBucket{start: start}
+	}
+	b.calls++
+	if throttled {
+		b.throttled++
+	}
+	t.mu.Unlock()
+}
+
+// ThrottleRate returns the fraction of attempts throttled within the window,
+// 0 when there were no attempts
+func (t *ThrottleTracker) ThrottleRate() float64 {
+	calls, throttled := t.counts(time.Now())
+	if calls == 0 {
+		return 0
+	}
+	return float64(throttled) / float64(calls)
+}
+
+// Throttles returns the number of attempts throttled within the window
+func (t *ThrottleTracker) Throttles() int64 {
+	_, throttled := t.counts(time.Now())
+	return throttled
+}
+
+func (t *ThrottleTracker) counts(now time.Time) (calls, throttled int64) {
+	oldest := now.Truncate(t.width).Add(-t.width * time.Duration(len(t.buckets)-1))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range t.buckets {
+		if !b.start.Before(oldest) {
+			calls += b.calls
+			throttled += b.throttled
+		}
+	}
+	return calls, throttled
+}
+
+var throttles *ThrottleTracker
+
+// SetThrottleTracker reports the outcome of every request attempt of the
+// clients built by NewClient, or passed to ObserveThrottles, to t, nil
+// detaches it. It should be called before the clients are used.
+func SetThrottleTracker(t *ThrottleTracker) {
+	throttles = t
+}
+
+// ObserveThrottles reports the attempts of a client built without NewClient
+// to the tracker set with SetThrottleTracker. It must be called once per
+// client, NewClient already does it.
+func ObserveThrottles(client *dynamodb.DynamoDB) {
+	client.Handlers.CompleteAttempt.PushBack(observeAttempt)
+}
+
+// observeAttempt runs after each attempt of a request, before the SDK decides
+// whether to retry it
+func observeAttempt(r *request.Request) {
+	if t := throttles; t != nil {
+		t.observe(time.Now(), isThrottle(r.Error))
+	}
+}
@@ -0,0 +1,49 @@
+package dynamodb
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestThrottleTrackerObservesRetries(t *testing.T) {
+	tracker := NewThrottleTracker(time.Minute)
+	SetThrottleTracker(tracker)
+	defer SetThrottleTracker(nil)
+	attempts := 0
+	client := newFakeClient(t, func(op string, body []byte) (int, interface{}) {
+		attempts++
+		if attempts == 1 {
+			return http.StatusBadRequest, fakeError(dynamodb.ErrCodeProvisionedThroughputExceededException)
+		}
+		return http.StatusOK, dynamodb.PutItemOutput{}
+	}, WithMaxRetries(1))
+	_, err := client.PutItem(&dynamodb.PutItemInput{TableName: aws.String("t"), Item: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the call succeeded after a retry the SDK hides from the caller
+	if n := tracker.Throttles(); n != 1 {
+		t.Errorf("got %d throttles, want 1", n)
+	}
+	if r := tracker.ThrottleRate(); r != 0.5 {
+		t.Errorf("got rate %v, want 0.5", r)
+	}
+}
+
+func TestNewThrottleTrackerWindow(t *testing.T) {
+	for _, window := range []time.Duration{-time.Second, 0, time.Nanosecond, 300 * time.Millisecond, 90 * time.Second} {
+		tracker := NewThrottleTracker(window)
+		if tracker.width <= 0 {
+			t.Errorf("window %v: got bucket width %v", window, tracker.width)
+			continue
+		}
+		tracker.observe(time.Now(), true)
+		if n := tracker.Throttles(); window >= time.Second && n != 1 {
+			t.Errorf("window %v: got %d throttles, want 1", window, n)
+		}
+	}
+}
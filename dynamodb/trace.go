@@ -0,0 +1,129 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/saidmu/acloud/dynamodb"
+
+var tracer trace.Tracer = noop.NewTracerProvider().Tracer(instrumentationName)
+
+// SetTracerProvider makes every helper start a span named dynamodb.<Helper>,
+// nil restores the no-op default. It should be called before the helpers are used.
+func SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	tracer = tp.Tracer(instrumentationName)
+}
+
+// operation tracks one helper call for tracing and metrics
+type operation struct {
+	name     string
+	table    string
+	opts     Options
+	write    bool
+	start    time.Time
+	span     trace.Span
+	count    int
+	capacity float64
+	pages    int
+	retries  int
+	// keyNames caches the key attributes of the tables the operation
+	// described
+	keyNames map[string][]string
+}
+
+// startOp starts the span of a helper call as a child of ctx
+// table: empty when the operation doesn't name a table up front
+func startOp(ctx context.Context, name, table string, opts []Option) (context.Context, *operation) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "dynamodb"),
+		attribute.String("db.operation", name),
+	}
+	if table != "" {
+		attrs = append(attrs, attribute.StringSlice("aws.dynamodb.table_names", []string{table}))
+	}
+	ctx, span := tracer.Start(ctx, "dynamodb."+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	o := newOptions(opts)
+	if o.defaultCapacity && (span.IsRecording() || o.Summary != nil) {
+		o.ReturnConsumedCapacity = dynamodb.ReturnConsumedCapacityTotal
+	}
+	return ctx, &operation{name: name, table: table, opts: o, start: time.Now(), span: span}
+}
+
+// addItems counts items read or written by the operation
+func (o *operation) addItems(n int) {
+	o.count += n
+}
+
+// addCapacity accumulates the consumed capacity of a request
+func (o *operation) addCapacity(cc ...*dynamodb.ConsumedCapacity) {
+	for _, c := range cc {
+		if c != nil {
+			o.capacity += aws.Float64Value(c.CapacityUnits)
+		}
+	}
+}
+
+// nextPage counts a request for another page, it returns
+// ErrPageLimitExceeded once more than MaxPages would be fetched
+func (o *operation) nextPage() error {
+	o.pages++
+	if o.opts.MaxPages > 0 && o.pages > o.opts.MaxPages {
+		return ErrPageLimitExceeded
+	}
+	return nil
+}
+
+// lastPage reports whether a loop over pages stops after the page ending at
+// last, always after the first with WithSinglePage
+func (o *operation) lastPage(last map[string]*dynamodb.AttributeValue) bool {
+	if o.opts.SinglePage != nil {
+		*o.opts.SinglePage = last
+		return true
+	}
+	return last == nil
+}
+
+// end finishes the operation, it is meant to be deferred with a pointer to
+// the named error result which it translates with translateError
+func (o *operation) end(err *error) {
+	if *err != nil {
+		*err = translateError(*err, o.table)
+	}
+	if o.write && o.opts.Cache != nil {
+		o.opts.Cache.Invalidate(o.table)
+	}
+	elapsed := time.Since(o.start)
+	if o.opts.Summary != nil {
+		*o.opts.Summary = Summary{Operation: o.name, Items: o.count, Pages: o.pages, Retries: o.retries, Capacity: o.capacity, Elapsed: elapsed, Err: *err}
+	}
+	m := o.opts.metrics()
+	m.ObserveLatency(o.name, elapsed)
+	o.span.SetAttributes(
+		attribute.Int("aws.dynamodb.item_count", o.count),
+		attribute.Float64("aws.dynamodb.consumed_capacity", o.capacity),
+	)
+	if *err != nil {
+		m.IncrError(o.name)
+		o.span.RecordError(*err)
+		o.span.SetStatus(codes.Error, (*err).Error())
+	}
+	o.span.End()
+}
+
+// touch reports the partition hit by item or key to the hot key tracker
+func (o *operation) touch(item map[string]*dynamodb.AttributeValue) {
+	if hotKeys != nil {
+		hotKeys.Observe(o.table, item)
+	}
+}
@@ -0,0 +1,105 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// MaxTransactItems is the most items one transaction accepts
+const MaxTransactItems = 100
+
+// TransactWrite applies all items atomically with TransactWriteItems. A
+// transaction cancelled by a failed condition returns ErrConditionFailed.
+func TransactWrite(ctx context.Context, client *dynamodb.DynamoDB, items []*dynamodb.TransactWriteItem, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "TransactWrite", "", opts)
+	defer op.end(&err)
+	return transactWrite(ctx, client, items, op)
+}
+
+func transactWrite(ctx context.Context, client *dynamodb.DynamoDB, items []*dynamodb.TransactWriteItem, op *operation) error {
+	if len(items) > MaxTransactItems {
+		return fmt.Errorf("dynamodb: %d transaction items exceed the limit of %d", len(items), MaxTransactItems)
+	}
+	input := &dynamodb.TransactWriteItemsInput{
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TransactItems:          items,
+	}
+	op.write = true
+	result, err := client.TransactWriteItemsWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	op.addItems(len(items))
+	op.addCapacity(result.ConsumedCapacity...)
+	return nil
+}
+
+// TransactPut builds a transaction item writing item when condition holds
+// condition: an unset expression.ConditionBuilder writes unconditionally
+func TransactPut(table string, item map[string]*dynamodb.AttributeValue, condition expression.ConditionBuilder) (*dynamodb.TransactWriteItem, error) {
+	put := &dynamodb.Put{Item: item, TableName: aws.String(table)}
+	if isSet(condition) {
+		expr, err := exprParts{condition: &condition}.build()
+		if err != nil {
+			return nil, err
+		}
+		put.ConditionExpression = expr.Condition()
+		put.ExpressionAttributeNames = expr.Names()
+		put.ExpressionAttributeValues = expr.Values()
+	}
+	return &dynamodb.TransactWriteItem{Put: put}, nil
+}
+
+// ConditionCheck builds a transaction item asserting condition on an item the
+// transaction doesn't modify, e.g. that a parent exists
+func ConditionCheck(table string, key map[string]*dynamodb.AttributeValue, condition expression.ConditionBuilder) (*dynamodb.TransactWriteItem, error) {
+	expr, err := exprParts{condition: &condition}.build()
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       key,
+		TableName:                 aws.String(table),
+	}}, nil
+}
+
+// TransactGet reads the items in one consistent snapshot with
+// TransactGetItems, the results follow the order of items and a missing item
+// is nil in its place
+func TransactGet(ctx context.Context, client *dynamodb.DynamoDB, items []*dynamodb.TransactGetItem, opts ...Option) (_ []map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "TransactGet", "", opts)
+	defer op.end(&err)
+	input := &dynamodb.TransactGetItemsInput{
+		ReturnConsumedCapacity: aws.String(op.opts.ReturnConsumedCapacity),
+		TransactItems:          items,
+	}
+	result, err := client.TransactGetItemsWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	op.addCapacity(result.ConsumedCapacity...)
+	output := make([]map[string]*dynamodb.AttributeValue, len(result.Responses))
+	for i, response := range result.Responses {
+		if response.Item == nil {
+			continue
+		}
+		op.addItems(1)
+		output[i] = response.Item
+	}
+	return output, nil
+}
+
+// TransactGetItem builds a transaction item reading the record with key
+func TransactGetItem(table string, key map[string]*dynamodb.AttributeValue) *dynamodb.TransactGetItem {
+	return &dynamodb.TransactGetItem{Get: &dynamodb.Get{
+		Key:       key,
+		TableName: aws.String(table),
+	}}
+}
@@ -0,0 +1,27 @@
+package dynamodb
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ExpiresAt reads the TTL attribute of item and returns when it expires and
+// whether that time has passed. DynamoDB deletes expired items lazily, up to
+// a few days late, so reads can still return them and should check expired.
+// ok is false if the attribute is missing or isn't a Number of Unix seconds,
+// in which case DynamoDB never expires the item.
+// ttlAttr: TTL attribute configured on the table
+func ExpiresAt(item map[string]*dynamodb.AttributeValue, ttlAttr string) (at time.Time, expired, ok bool) {
+	av := item[ttlAttr]
+	if av == nil || av.N == nil {
+		return time.Time{}, false, false
+	}
+	secs, err := strconv.ParseInt(*av.N, 10, 64)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	at = time.Unix(secs, 0)
+	return at, !at.After(time.Now()), true
+}
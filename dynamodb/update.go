@@ -0,0 +1,300 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// AddNumberReturning adds number to the attribute like AddNumber and returns
+// the new value in the same round trip
+func AddNumberReturning(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, number int64, opts ...Option) (_ int64, err error) {
+	ctx, op := startOp(ctx, "AddNumberReturning", table, opts)
+	defer op.end(&err)
+	return addNumberReturning(ctx, client, table, key, name, number, op)
+}
+
+// NextSequence atomically increments the counter attribute of the item and
+// returns the new value, for generating monotonic IDs. A missing counter item
+// is created and the sequence starts at 1.
+func NextSequence(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, attr string, opts ...Option) (_ int64, err error) {
+	ctx, op := startOp(ctx, "NextSequence", table, opts)
+	defer op.end(&err)
+	return addNumberReturning(ctx, client, table, key, attr, 1, op)
+}
+
+// UpdateWithCondition applies any update when condition holds, e.g. add 10 to
+// balance only if balance >= 10, otherwise it returns ErrConditionFailed
+func UpdateWithCondition(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, condition expression.ConditionBuilder, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "UpdateWithCondition", table, opts)
+	defer op.end(&err)
+	_, err = updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	return err
+}
+
+// AddNumberIf adds number to the attribute like AddNumber only when condition
+// holds, otherwise it returns ErrConditionFailed
+func AddNumberIf(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, number int64, condition expression.ConditionBuilder, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "AddNumberIf", table, opts)
+	defer op.end(&err)
+	update := expression.Add(expression.Name(name), expression.Value(number))
+	_, err = updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	return err
+}
+
+// SetNumberIfChanged sets the attribute to value only if it holds another
+// value or is missing and reports whether it wrote, so syncs re-writing
+// unchanged values don't spend write capacity on no-ops. WithConditionError
+// returns ErrConditionFailed instead of false.
+func SetNumberIfChanged(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, value int64, opts ...Option) (_ bool, err error) {
+	ctx, op := startOp(ctx, "SetNumberIfChanged", table, opts)
+	defer op.end(&err)
+	update := expression.Set(expression.Name(name), expression.Value(value))
+	condition := expression.Name(name).AttributeNotExists().Or(expression.Name(name).NotEqual(expression.Value(value)))
+	_, err = updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	if isConditionFailed(err) && !op.opts.ConditionError {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpdateRecord applies update and returns the whole item as it is after the
+// update, saving a follow-up GetItem
+func UpdateRecord(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, opts ...Option) (_ map[string]*dynamodb.AttributeValue, err error) {
+	ctx, op := startOp(ctx, "UpdateRecord", table, opts)
+	defer op.end(&err)
+	return updateItem(ctx, client, table, key, update, expression.ConditionBuilder{}, dynamodb.ReturnValueAllNew, op)
+}
+
+// UpdateRecordTyped applies update like UpdateRecord and unmarshals the item
+// after the update into T
+func UpdateRecordTyped[T any](ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, opts ...Option) (_ T, err error) {
+	ctx, op := startOp(ctx, "UpdateRecordTyped", table, opts)
+	defer op.end(&err)
+	var value T
+	attributes, err := updateItem(ctx, client, table, key, update, expression.ConditionBuilder{}, dynamodb.ReturnValueAllNew, op)
+	if err != nil {
+		return value, err
+	}
+	if err := unmarshalItem(attributes, &value, op.opts.Coercions); err != nil {
+		return value, &ItemError{Key: key, Err: err}
+	}
+	return value, nil
+}
+
+// SetListElement sets the element at index idx of a list attribute, e.g. one
+// bucket of a fixed-size daily array. Unlike a plain SET, which appends when
+// the index is past the end, it returns ErrIndexOutOfRange and leaves the list alone.
+func SetListElement(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, attr string, idx int, value interface{}, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "SetListElement", table, opts)
+	defer op.end(&err)
+	if idx < 0 {
+		return fmt.Errorf("%w: %s[%d]", ErrIndexOutOfRange, attr, idx)
+	}
+	update := expression.Set(expression.Name(fmt.Sprintf("%s[%d]", attr, idx)), expression.Value(value))
+	condition := expression.Name(attr).Size().GreaterThan(expression.Value(idx))
+	_, err = updateItem(ctx, client, table, key, update, condition, dynamodb.ReturnValueNone, op)
+	if isConditionFailed(err) {
+		return fmt.Errorf("%w: %s[%d]", ErrIndexOutOfRange, attr, idx)
+	}
+	return err
+}
+
+// AddNumberToMapKey adds delta to the number stored under mapKey in a map
+// attribute, e.g. counts.apple += 1, creating the map when it is missing.
+// mapKey is sent as its own #placeholder so dots and brackets in it are not
+// taken for a nested path.
+func AddNumberToMapKey(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, mapAttr, mapKey string, delta int64, opts ...Option) (err error) {
+	ctx, op := startOp(ctx, "AddNumberToMapKey", table, opts)
+	defer op.end(&err)
+	names := map[string]*string{"#m": aws.String(mapAttr), "#k": aws.String(mapKey)}
+	number := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(delta, 10))}
+	// ADD on a nested path fails when the map is missing and a single update
+	// can't create the map and add to it, so add to an existing map first and
+	// create it otherwise. The map can appear in between, hence the retry.
+	for attempt := 0; attempt < 3; attempt++ {
+		add := &dynamodb.UpdateItemInput{
+			ConditionExpression:       aws.String("attribute_exists(#m)"),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":d": number},
+			Key:                       key,
+			ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+			TableName:                 aws.String(table),
+			UpdateExpression:          aws.String("ADD #m.#k :d"),
+		}
+		op.write = true
+		result, err := client.UpdateItemWithContext(ctx, add)
+		if err == nil {
+			op.addItems(1)
+			op.touch(key)
+			op.addCapacity(result.ConsumedCapacity)
+			return nil
+		}
+		if !isConditionFailed(err) {
+			return err
+		}
+		create := &dynamodb.UpdateItemInput{
+			ConditionExpression:       aws.String("attribute_not_exists(#m)"),
+			ExpressionAttributeNames:  map[string]*string{"#m": aws.String(mapAttr)},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":m": {M: map[string]*dynamodb.AttributeValue{mapKey: number}}},
+			Key:                       key,
+			ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+			TableName:                 aws.String(table),
+			UpdateExpression:          aws.String("SET #m = :m"),
+		}
+		result, err = client.UpdateItemWithContext(ctx, create)
+		if err == nil {
+			op.addItems(1)
+			op.touch(key)
+			op.addCapacity(result.ConsumedCapacity)
+			return nil
+		}
+		if !isConditionFailed(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("dynamodb: %s kept changing while adding to %s.%s", table, mapAttr, mapKey)
+}
+
+func addNumberReturning(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, name string, number int64, op *operation) (int64, error) {
+	update := expression.Add(expression.Name(name), expression.Value(number))
+	attributes, err := updateItem(ctx, client, table, key, update, expression.ConditionBuilder{}, dynamodb.ReturnValueUpdatedNew, op)
+	if err != nil {
+		return 0, err
+	}
+	var value int64
+	if err := dynamodbattribute.Unmarshal(attributes[name], &value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// updateItem applies update to the item when condition holds and returns the
+// attributes selected by returnValues
+// condition: an unset expression.ConditionBuilder updates unconditionally
+func updateItem(ctx context.Context, client *dynamodb.DynamoDB, table string, key map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, condition expression.ConditionBuilder, returnValues string, op *operation) (map[string]*dynamodb.AttributeValue, error) {
+	parts := exprParts{update: &update}
+	if isSet(condition) {
+		parts.condition = &condition
+	}
+	expr, err := parts.build()
+	if err != nil {
+		return nil, err
+	}
+	if op.opts.SizeCheck {
+		if err := checkUpdateSize(ctx, client, table, key, expr, op); err != nil {
+			return nil, err
+		}
+	}
+	input := &dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       key,
+		ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+		ReturnValues:              aws.String(returnValues),
+		TableName:                 aws.String(table),
+		UpdateExpression:          expr.Update(),
+	}
+	op.write = true
+	result, err := client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	op.addItems(1)
+	op.touch(key)
+	op.addCapacity(result.ConsumedCapacity)
+	return result.Attributes, nil
+}
+
+// UpdateMany applies the same update to every record of keys with up to
+// Concurrency UpdateItem calls at once, retrying throttled calls with the
+// Backoff of the call. It returns how many records it updated, the first
+// error stops the remaining updates.
+func UpdateMany(ctx context.Context, client *dynamodb.DynamoDB, table string, keys []map[string]*dynamodb.AttributeValue, update expression.UpdateBuilder, opts ...Option) (updated int, err error) {
+	ctx, op := startOp(ctx, "UpdateMany", table, opts)
+	defer op.end(&err)
+	expr, err := exprParts{update: &update}.build()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		key      map[string]*dynamodb.AttributeValue
+		capacity *dynamodb.ConsumedCapacity
+		attempts int
+		err      error
+	}
+	work := make(chan map[string]*dynamodb.AttributeValue)
+	results := make(chan result)
+	workers := op.opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				input := &dynamodb.UpdateItemInput{
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+					Key:                       key,
+					ReturnConsumedCapacity:    aws.String(op.opts.ReturnConsumedCapacity),
+					TableName:                 aws.String(table),
+					UpdateExpression:          expr.Update(),
+				}
+				var output *dynamodb.UpdateItemOutput
+				attempts := 0
+				err := Retry(ctx, func() (err error) {
+					attempts++
+					output, err = client.UpdateItemWithContext(ctx, input)
+					return err
+				}, op.opts.Backoff)
+				r := result{key: key, attempts: attempts, err: err}
+				if err == nil {
+					r.capacity = output.ConsumedCapacity
+				}
+				results <- r
+			}
+		}()
+	}
+	go func() {
+		defer close(work)
+		for _, key := range keys {
+			select {
+			case work <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	op.write = true
+	for r := range results {
+		op.retries += r.attempts - 1
+		if r.err != nil {
+			if err == nil {
+				err = r.err
+				cancel()
+			}
+			continue
+		}
+		updated++
+		op.addItems(1)
+		op.touch(r.key)
+		op.addCapacity(r.capacity)
+	}
+	return updated, err
+}
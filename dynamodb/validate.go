@@ -0,0 +1,166 @@
+package dynamodb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// MaxItemSize is the largest item DynamoDB accepts, in bytes
+	MaxItemSize = 400 * 1024
+	// MaxAttributeNameLength is the longest attribute name DynamoDB accepts, in bytes
+	MaxAttributeNameLength = 65535
+)
+
+var validateWrites bool
+
+// SetValidation turns on ValidateItem for every item the write helpers send,
+// it is off by default. It should be called before the helpers are used.
+func SetValidation(enabled bool) {
+	validateWrites = enabled
+}
+
+// ValidationError reports which attribute broke which DynamoDB rule
+// Attribute: path of the attribute, empty for rules on the whole item
+type ValidationError struct {
+	Attribute string
+	Rule      string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Attribute == "" {
+		return fmt.Sprintf("dynamodb: item %s", e.Rule)
+	}
+	return fmt.Sprintf("dynamodb: attribute %q %s", e.Attribute, e.Rule)
+}
+
+// ValidateItem checks the item against the DynamoDB limits on attribute names,
+// empty binary values, empty sets and item size, it returns a *ValidationError
+// for the first violation
+func ValidateItem(item map[string]*dynamodb.AttributeValue) error {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateValue(name, item[name]); err != nil {
+			return err
+		}
+	}
+	if size := ItemSize(item); size > MaxItemSize {
+		return &ValidationError{Rule: fmt.Sprintf("size %d bytes exceeds %d bytes", size, MaxItemSize)}
+	}
+	return nil
+}
+
+func validateValue(path string, v *dynamodb.AttributeValue) error {
+	last := path[strings.LastIndexAny(path, ".]")+1:]
+	switch {
+	case path == "":
+		return &ValidationError{Attribute: path, Rule: "has an empty name"}
+	case len(last) > MaxAttributeNameLength:
+		return &ValidationError{Attribute: path, Rule: fmt.Sprintf("name is longer than %d bytes", MaxAttributeNameLength)}
+	case v == nil:
+		return &ValidationError{Attribute: path, Rule: "has a nil value"}
+	case v.B != nil && len(v.B) == 0:
+		return &ValidationError{Attribute: path, Rule: "has an empty binary value"}
+	case v.SS != nil && len(v.SS) == 0, v.NS != nil && len(v.NS) == 0, v.BS != nil && len(v.BS) == 0:
+		return &ValidationError{Attribute: path, Rule: "is an empty set"}
+	}
+	for i, b := range v.BS {
+		if len(b) == 0 {
+			return &ValidationError{Attribute: fmt.Sprintf("%s[%d]", path, i), Rule: "has an empty binary value"}
+		}
+	}
+	for i, e := range v.L {
+		if err := validateValue(fmt.Sprintf("%s[%d]", path, i), e); err != nil {
+			return err
+		}
+	}
+	for name, e := range v.M {
+		if name == "" {
+			return &ValidationError{Attribute: path, Rule: "has a member with an empty name"}
+		}
+		if err := validateValue(path+"."+name, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate runs ValidateItem when SetValidation turned it on
+func validate(item map[string]*dynamodb.AttributeValue) error {
+	if !validateWrites {
+		return nil
+	}
+	return ValidateItem(item)
+}
+
+// ItemSize estimates the size of an item in bytes the way DynamoDB accounts
+// for it: attribute names plus values, with list and map overhead
+func ItemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := 0
+	for name, v := range item {
+		size += len(name) + valueSize(v)
+	}
+	return size
+}
+
+func valueSize(v *dynamodb.AttributeValue) int {
+	switch {
+	case v == nil:
+		return 0
+	case v.S != nil:
+		return len(*v.S)
+	case v.N != nil:
+		return numberSize(*v.N)
+	case v.B != nil:
+		return len(v.B)
+	case v.BOOL != nil, v.NULL != nil:
+		return 1
+	case v.SS != nil:
+		size := 0
+		for _, s := range v.SS {
+			size += len(*s)
+		}
+		return size
+	case v.NS != nil:
+		size := 0
+		for _, n := range v.NS {
+			size += numberSize(*n)
+		}
+		return size
+	case v.BS != nil:
+		size := 0
+		for _, b := range v.BS {
+			size += len(b)
+		}
+		return size
+	case v.L != nil:
+		size := 3
+		for _, e := range v.L {
+			size += 1 + valueSize(e)
+		}
+		return size
+	case v.M != nil:
+		size := 3
+		for name, e := range v.M {
+			size += 1 + len(name) + valueSize(e)
+		}
+		return size
+	}
+	return 0
+}
+
+// numberSize is one byte per two significant digits plus one byte
+func numberSize(n string) int {
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+	digits := strings.Trim(strings.NewReplacer("-", "", "+", "", ".", "").Replace(n), "0")
+	return (len(digits)+1)/2 + 1
+}
@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// BatchWriter buffers records and writes them in batches of 25, as soon as a
+// batch fills up or when the flush interval elapses. It is safe for
+// concurrent use. Write errors are collected and returned together by the
+// next Flush or Close.
+type BatchWriter struct {
+	client  *dynamodb.DynamoDB
+	table   string
+	opts    []Option
+	mu      sync.Mutex
+	pending []*dynamodb.WriteRequest
+	errs    []error
+	closed  bool
+	writing sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewBatchWriter func returns a writer to table flushing every interval
+// interval: 0 disables time based flushing
+func NewBatchWriter(client *dynamodb.DynamoDB, table string, interval time.Duration, opts ...Option) *BatchWriter {
+	w := &BatchWriter{client: client, table: table, opts: opts, stop: make(chan struct{}), done: make(chan struct{})}
+	go w.run(interval)
+	return w
+}
+
+func (w *BatchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	if interval <= 0 {
+		<-w.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.drain()
+		}
+	}
+}
+
+// Add buffers one record and writes the batch once it holds 25 records
+func (w *BatchWriter) Add(item map[string]*dynamodb.AttributeValue) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.pending = append(w.pending, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}})
+	w.mu.Unlock()
+	w.write(w.take(MaxBatchWriteItems))
+	return nil
+}
+
+// Flush writes the buffered records and returns the errors collected since
+// the previous Flush
+func (w *BatchWriter) Flush() error {
+	w.drain()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := errors.Join(w.errs...)
+	w.errs = nil
+	return err
+}
+
+// Close stops the flush timer and writes the remaining records, Add fails
+// afterwards
+func (w *BatchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.stop)
+	<-w.done
+	return w.Flush()
+}
+
+// drain writes every buffered record
+func (w *BatchWriter) drain() {
+	for batch := w.take(1); batch != nil; batch = w.take(1) {
+		w.write(batch)
+	}
+}
+
+// take removes up to 25 buffered records when at least min are buffered
+func (w *BatchWriter) take(min int) []*dynamodb.WriteRequest {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) < min || len(w.pending) == 0 {
+		return nil
+	}
+	n := len(w.pending)
+	if n > MaxBatchWriteItems {
+		n = MaxBatchWriteItems
+	}
+	batch := w.pending[:n:n]
+	w.pending = w.pending[n:]
+	return batch
+}
+
+// write sends one batch and keeps its error for Flush
+func (w *BatchWriter) write(batch []*dynamodb.WriteRequest) {
+	if batch == nil {
+		return
+	}
+	w.writing.Lock()
+	defer w.writing.Unlock()
+	err := w.writeBatch(batch)
+	if err != nil {
+		w.mu.Lock()
+		w.errs = append(w.errs, err)
+		w.mu.Unlock()
+	}
+}
+
+func (w *BatchWriter) writeBatch(batch []*dynamodb.WriteRequest) (err error) {
+	ctx, op := startOp(context.Background(), "BatchWriter", w.table, w.opts)
+	defer op.end(&err)
+	return batchWrite(ctx, w.client, w.table, batch, op)
+}